@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/user/go-brew-search/internal/api"
+)
+
+var addCommand = &command{
+	name:    "add",
+	args:    "<package>...",
+	summary: "Add packages to the Brewfile by name",
+	flags: func(fs *flag.FlagSet) func(a *app, args []string) error {
+		pkgType := fs.String("type", "", "Only consider packages of this type (formula or cask)")
+		noInstall := fs.Bool("no-install", false, "Only update the Brewfile; don't run brew bundle")
+
+		return func(a *app, args []string) error {
+			return runAdd(a, args, *pkgType, !*noInstall)
+		}
+	},
+}
+
+// runAdd adds named packages to the Brewfile
+func runAdd(a *app, names []string, pkgType string, bundle bool) error {
+	if len(names) == 0 {
+		return usageErrorf("add needs at least one package name")
+	}
+	if err := checkType(pkgType); err != nil {
+		return err
+	}
+
+	existing, err := a.loadExisting()
+	if err != nil {
+		return fmt.Errorf("failed to load Brewfile: %w", err)
+	}
+
+	packages, err := a.allPackages()
+	if err != nil {
+		return fmt.Errorf("failed to fetch packages: %w", err)
+	}
+
+	resolver := api.NewResolver(packages)
+	var selected []api.Package
+	for _, name := range names {
+		pkg, err := resolveOne(a, resolver, name, pkgType)
+		if err != nil {
+			return err
+		}
+		selected = append(selected, pkg)
+	}
+
+	return addToBrewfile(a, selected, existing, bundle)
+}
+
+// resolveOne finds the single package a name refers to, so the Brewfile
+// gets its canonical name rather than an alias brew bundle may not accept
+func resolveOne(a *app, resolver *api.Resolver, name, pkgType string) (api.Package, error) {
+	res := resolver.Resolve(name, pkgType)
+	switch len(res.Packages) {
+	case 0:
+		return api.Package{}, fmt.Errorf("no package named %q%s", name, didYouMean(res))
+	case 1:
+		reportResolution(a, res)
+		return res.Packages[0], nil
+	}
+	return api.Package{}, usageErrorf("%q is both a formula and a cask; use -type formula or -type cask", name)
+}
+
+// reportResolution says which package a name stood for, unless it was the
+// package's own name
+func reportResolution(a *app, res api.Resolution) {
+	if len(res.Packages) == 0 || res.Packages[0].Token == res.Name {
+		return
+	}
+	if res.Canonical() {
+		a.infof("🔗 %s resolves to %s", res.Name, res.Packages[0].Token)
+		return
+	}
+	a.infof("🔗 %s is an %s of %s", res.Name, res.Match, res.Packages[0].Token)
+}
+
+// didYouMean lists the suggestions of a failed resolution for an error
+// message, or returns "" when there are none
+func didYouMean(res api.Resolution) string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, pkg := range res.Suggestions {
+		if !seen[pkg.Token] {
+			seen[pkg.Token] = true
+			names = append(names, pkg.Token)
+		}
+	}
+
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return "; did you mean " + names[0] + "?"
+	}
+	return "; did you mean " + strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1] + "?"
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/user/go-brew-search/internal/cache"
+	"github.com/user/go-brew-search/internal/ui"
+)
+
+var cacheCommand = &command{
+	name:    "cache",
+	args:    "stats|refresh|prune|clear|export <file>|import <file>",
+	summary: "Manage the local package cache",
+	flags: func(fs *flag.FlagSet) func(a *app, args []string) error {
+		return runCache
+	},
+}
+
+const cacheUsage = "usage: brew-search cache stats|refresh|prune|clear|export <file>|import <file>"
+
+// pruneInterval is how often commands prune the cache in the background
+const pruneInterval = time.Hour
+
+// pruneMarker is touched in the cache directory after each prune
+const pruneMarker = "last-prune"
+
+// runCache dispatches cache subcommands
+func runCache(a *app, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf(cacheUsage)
+	}
+
+	switch args[0] {
+	case "stats", "refresh", "prune", "clear":
+		if len(args) != 1 {
+			return usageErrorf(cacheUsage)
+		}
+	case "export", "import":
+		if len(args) != 2 {
+			return usageErrorf("usage: brew-search cache %s <file>", args[0])
+		}
+	default:
+		return usageErrorf("unknown cache command: %s", args[0])
+	}
+
+	switch args[0] {
+	case "stats":
+		return cacheStats(a)
+	case "refresh":
+		return cacheRefresh(a)
+	case "prune":
+		return cachePrune(a)
+	case "export":
+		return cacheExport(a, args[1])
+	case "import":
+		return cacheImport(a, args[1])
+	}
+
+	if a.dryRun {
+		fmt.Printf("🗑️  Would clear the cache in %s\n", a.cache.Dir())
+		return nil
+	}
+	if err := a.cache.Clear(); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	fmt.Println("✅ Cache cleared!")
+	return nil
+}
+
+// cacheStats prints every cached entry with its size, age and time to
+// expiry
+func cacheStats(a *app) error {
+	infos, err := a.cache.Entries()
+	if err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	policy := a.api.Policy()
+	fmt.Printf("📁 Cache: %s (TTL %s)\n", a.cache.Dir(), policy.TTL)
+	if len(infos) == 0 {
+		fmt.Println("📭 The cache is empty")
+		return printQuarantined(a)
+	}
+	fmt.Println()
+
+	var total int64
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tSIZE\tAGE\tEXPIRES IN\tLOCATION")
+	for _, info := range infos {
+		ttl := policy.TTLFor(info.Key)
+		expires := ui.FormatAge(time.Until(info.Stored.Add(ttl)))
+		switch {
+		case ttl == 0:
+			expires = "never"
+		case !policy.Fresh(info.Key, info.Stored):
+			expires = "expired"
+		}
+
+		location := "own"
+		if info.Shared {
+			location = "shared"
+		} else {
+			total += info.Size
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", info.Key, formatSize(info.Size), ui.FormatAge(time.Since(info.Stored)), expires, location)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n📊 %s in the own cache\n", formatSize(total))
+	return printQuarantined(a)
+}
+
+// printQuarantined lists corrupt entries that were moved aside
+func printQuarantined(a *app) error {
+	quarantined, err := a.cache.Quarantined()
+	if err != nil || len(quarantined) == 0 {
+		return err
+	}
+
+	fmt.Printf("\n⚠️  %d corrupt entries were quarantined (removed by cache clear):\n", len(quarantined))
+	for _, path := range quarantined {
+		fmt.Printf("   %s\n", path)
+	}
+	return nil
+}
+
+// cachePrune removes expired and least recently used package details and
+// files left behind by interrupted or older versions
+func cachePrune(a *app) error {
+	if a.dryRun {
+		fmt.Printf("🧹 Would prune the cache in %s\n", a.cache.Dir())
+		return nil
+	}
+
+	// Let a background prune finish first so the counts are accurate
+	a.pruning()
+	keys, files, err := a.prune(time.Minute)
+	if err != nil {
+		return fmt.Errorf("failed to prune cache: %w", err)
+	}
+	fmt.Printf("✅ Cache pruned: %d entries and %d stale files removed\n", len(keys), len(files))
+	return nil
+}
+
+// prune applies the cache policy and removes orphaned files older than
+// orphanAge, then records when it ran
+func (a *app) prune(orphanAge time.Duration) (keys, files []string, err error) {
+	keys, err = cache.Prune(a.cache, a.api.Policy())
+	if err != nil {
+		return keys, nil, err
+	}
+	files, err = a.cache.RemoveOrphans(orphanAge)
+	if err != nil {
+		return keys, files, err
+	}
+
+	marker := filepath.Join(a.cache.Dir(), pruneMarker)
+	if f, err := os.Create(marker); err == nil {
+		f.Close()
+	}
+	return keys, files, nil
+}
+
+// startPrune prunes the cache in the background when it was last pruned
+// more than pruneInterval ago. The returned function waits for it to
+// finish.
+func (a *app) startPrune() func() {
+	info, err := os.Stat(filepath.Join(a.cache.Dir(), pruneMarker))
+	if err == nil && time.Since(info.ModTime()) < pruneInterval {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// Temporary files younger than this may belong to a running write
+		keys, files, err := a.prune(pruneInterval)
+		if err != nil {
+			a.debugf("Cache pruning failed: %v", err)
+			return
+		}
+		a.debugf("Pruned %d cache entries and %d stale files", len(keys), len(files))
+	}()
+	return func() { <-done }
+}
+
+// cacheRefresh downloads the package data again regardless of its age
+func cacheRefresh(a *app) error {
+	if a.dryRun {
+		fmt.Printf("🔄 Would download package data into %s\n", a.cache.Dir())
+		return nil
+	}
+
+	a.infof("🔄 Downloading package data...")
+	packages, err := a.api.Refresh()
+	if err != nil {
+		return fmt.Errorf("failed to refresh cache: %w", err)
+	}
+
+	formulae := 0
+	for _, pkg := range packages {
+		if pkg.Type == "formula" {
+			formulae++
+		}
+	}
+	fmt.Printf("✅ Cache refreshed: %d formulae, %d casks\n", formulae, len(packages)-formulae)
+	return nil
+}
+
+// cacheExport writes the cache to a single archive, or to stdout for "-"
+func cacheExport(a *app, file string) error {
+	if a.dryRun {
+		fmt.Printf("📦 Would export the cache to %s\n", file)
+		return nil
+	}
+
+	var w io.Writer = os.Stdout
+	if file != "-" {
+		f, err := os.Create(file)
+		if err != nil {
+			return fmt.Errorf("failed to create archive: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	infos, err := a.cache.Export(w)
+	if err != nil {
+		if file != "-" {
+			os.Remove(file)
+		}
+		return fmt.Errorf("failed to export cache: %w", err)
+	}
+	if len(infos) == 0 {
+		a.infof("⚠️  The cache is empty; the archive has no entries")
+	}
+
+	a.infof("✅ Exported %d cache entries to %s", len(infos), file)
+	return nil
+}
+
+// cacheImport replaces cache entries with those from an archive written by
+// cache export, or read from stdin for "-"
+func cacheImport(a *app, file string) error {
+	if a.dryRun {
+		fmt.Printf("📦 Would import %s into %s\n", file, a.cache.Dir())
+		return nil
+	}
+
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	keys, err := a.cache.Import(r)
+	if err != nil {
+		return fmt.Errorf("failed to import cache: %w", err)
+	}
+
+	a.infof("✅ Imported %d cache entries into %s", len(keys), a.cache.Dir())
+	return nil
+}
+
+// formatSize renders a byte count as B, KB or MB
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
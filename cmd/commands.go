@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// command is a brew-search subcommand
+type command struct {
+	name    string
+	args    string // positional arguments shown in help
+	summary string
+	// flags defines the command's own flags and returns its run function
+	flags func(fs *flag.FlagSet) func(a *app, args []string) error
+
+	fs  *flag.FlagSet
+	run func(a *app, args []string) error
+}
+
+// usageError is returned when a command is called with bad arguments. It
+// exits with exitUsage.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// commands lists every subcommand in the order shown in help
+var commands = []*command{
+	searchCommand,
+	infoCommand,
+	whatsNewCommand,
+	addCommand,
+	removeCommand,
+	syncCommand,
+	statusCommand,
+	outdatedCommand,
+	importCommand,
+	lintCommand,
+	fmtCommand,
+	cacheCommand,
+	configCommand,
+}
+
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// flagSet builds the command's flag set and run function
+func (c *command) flagSet() *flag.FlagSet {
+	if c.fs != nil {
+		return c.fs
+	}
+
+	c.fs = flag.NewFlagSet(c.name, flag.ContinueOnError)
+	c.run = c.flags(c.fs)
+	c.fs.Usage = func() {
+		out := c.fs.Output()
+		fmt.Fprintf(out, "%s\n\nUsage:\n  brew-search %s [flags] %s\n\nFlags:\n", c.summary, c.name, c.args)
+		c.fs.PrintDefaults()
+	}
+	return c.fs
+}
+
+func printUsage(root *flag.FlagSet) {
+	out := root.Output()
+	fmt.Fprintln(out, "🍺 go-brew-search: fast, interactive Homebrew package search")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Usage:")
+	fmt.Fprintln(out, "  brew-search [flags]                  Search interactively and add to the Brewfile")
+	fmt.Fprintln(out, "  brew-search [flags] <command> [args]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Commands:")
+
+	width := 0
+	for _, cmd := range commands {
+		width = max(width, len(cmd.name))
+	}
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-*s  %s\n", width, cmd.name, cmd.summary)
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Flags:")
+	root.PrintDefaults()
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Run 'brew-search help <command>' for details on a command.")
+}
+
+// runHelp prints help for the whole tool or for one command. A command's
+// help lists the global flags too, as "<command> -h" does.
+func runHelp(g *globalOptions, root *flag.FlagSet, args []string) int {
+	root.SetOutput(os.Stdout)
+	if len(args) == 0 {
+		printUsage(root)
+		return exitOK
+	}
+
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "❌ Unknown command: %s\n", strings.Join(args, " "))
+		return exitUsage
+	}
+
+	fs := cmd.flagSet()
+	g.register(fs)
+	fs.SetOutput(os.Stdout)
+	fs.Usage()
+	return exitOK
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommandsRejectExtraArguments(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("BREW_SEARCH_CONFIG", "")
+	t.Setenv("HOMEBREW_BREW_FILE", "")
+
+	brewfile := filepath.Join(home, "Brewfile")
+	content := "brew \"wget\"\nbrew \"jq\"\n"
+	if err := os.WriteFile(brewfile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("BREW_SEARCH_BREWFILE", brewfile)
+
+	for _, name := range []string{"config", "fmt", "import", "lint", "outdated", "status", "sync", "whatsnew"} {
+		if code := run([]string{"-offline", name, "Brewfile.work"}); code != exitUsage {
+			t.Errorf("%s with an extra argument exited %d, want %d", name, code, exitUsage)
+		}
+	}
+
+	if data, err := os.ReadFile(brewfile); err != nil || string(data) != content {
+		t.Errorf("Brewfile = %q, %v, want it untouched", data, err)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+)
+
+var configCommand = &command{
+	name:    "config",
+	summary: "Show the effective configuration and where each value came from",
+	flags: func(fs *flag.FlagSet) func(a *app, args []string) error {
+		jsonOut := fs.Bool("json", false, "Print the configuration as JSON")
+
+		return func(a *app, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("config takes no arguments")
+			}
+			return runConfig(a, *jsonOut)
+		}
+	},
+}
+
+// runConfig prints the merged configuration
+func runConfig(a *app, jsonOut bool) error {
+	settings := a.config.Settings()
+
+	if jsonOut {
+		type setting struct {
+			Key    string `json:"key"`
+			Value  string `json:"value"`
+			Source string `json:"source"`
+			Origin string `json:"origin,omitempty"`
+		}
+		out := make([]setting, len(settings))
+		for i, s := range settings {
+			out[i] = setting{s.Key, s.Value, s.Source, s.Origin}
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	exists := "not found"
+	if _, err := os.Stat(a.config.Path); err == nil {
+		exists = "loaded"
+	}
+	fmt.Printf("📄 Config file: %s (%s)\n\n", a.config.Path, exists)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, s := range settings {
+		source := s.Source
+		if s.Origin != "" {
+			source += " (" + s.Origin + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, s.Value, source)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"strings"
+)
+
+// parseInterspersed parses flags that may appear before or after positional
+// arguments (e.g. "search jq -json") and returns the positional arguments.
+// Everything after "--" is positional.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		// Stop before "-in:brewfile" and the like, which the flag package
+		// would take for an unknown flag
+		n := len(args)
+		for i, arg := range args {
+			if arg == "--" {
+				break
+			}
+			if isNegatedQualifier(arg) {
+				n = i
+				break
+			}
+		}
+
+		if err := fs.Parse(args[:n]); err != nil {
+			return nil, err
+		}
+
+		consumed := n - len(fs.Args())
+		if consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, args[consumed:]...), nil
+		}
+		if consumed == len(args) {
+			return positional, nil
+		}
+
+		positional = append(positional, args[consumed])
+		args = args[consumed+1:]
+	}
+}
+
+// isNegatedQualifier reports whether arg is a search qualifier such as
+// "-in:brewfile" rather than a flag. Flag names never contain ":".
+func isNegatedQualifier(arg string) bool {
+	name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+	return strings.HasPrefix(arg, "-") && strings.Contains(name, ":")
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+var fmtCommand = &command{
+	name:    "fmt",
+	summary: "Sort and group the Brewfile into taps, formulae and casks",
+	flags: func(fs *flag.FlagSet) func(a *app, args []string) error {
+		check := fs.Bool("check", false, "Don't write; fail if the Brewfile isn't formatted")
+
+		return func(a *app, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("fmt takes no arguments")
+			}
+			return runFmt(a, *check)
+		}
+	},
+}
+
+// runFmt formats the Brewfile in place
+func runFmt(a *app, check bool) error {
+	if check {
+		formatted, err := a.brewfile.IsFormatted()
+		if err != nil {
+			return fmt.Errorf("failed to read Brewfile: %w", err)
+		}
+		if !formatted {
+			return fmt.Errorf("%s is not formatted; run 'brew-search fmt'", a.brewfile.Path())
+		}
+		return nil
+	}
+
+	if err := a.brewfile.Format(); err != nil {
+		return fmt.Errorf("failed to format Brewfile: %w", err)
+	}
+
+	if !a.dryRun {
+		a.infof("✅ Formatted %s", a.brewfile.Path())
+	}
+	return nil
+}
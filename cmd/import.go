@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/brew"
+	"github.com/user/go-brew-search/internal/brewfile"
+	"github.com/user/go-brew-search/internal/ui"
+)
+
+var importCommand = &command{
+	name:    "import",
+	summary: "Build a Brewfile from the packages installed on this machine",
+	flags: func(fs *flag.FlagSet) func(a *app, args []string) error {
+		force := fs.Bool("force", false, "Overwrite an existing Brewfile")
+		yes := fs.Bool("yes", false, "Write the Brewfile without asking for confirmation")
+
+		return func(a *app, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("import takes no arguments")
+			}
+			return runImport(a, *force, *yes)
+		}
+	},
+}
+
+// runImport builds a Brewfile from the packages installed on this machine
+func runImport(a *app, force, yes bool) error {
+	existing, err := a.brewfile.Entries()
+	if err != nil {
+		return fmt.Errorf("failed to load Brewfile: %w", err)
+	}
+	if len(existing) > 0 && !force {
+		return fmt.Errorf("%s already has %d entries; use -force to overwrite it or 'status -i' to add to it",
+			a.brewfile.Path(), len(existing))
+	}
+
+	a.infof("🔄 Checking installed packages...")
+	installed, err := brew.LoadInstalled(a.runner)
+	if err != nil {
+		return err
+	}
+
+	a.infof("🔄 Fetching Homebrew packages...")
+	packages, err := a.allPackages()
+	if err != nil {
+		return fmt.Errorf("failed to fetch packages: %w", err)
+	}
+
+	// Everything installed is unlisted relative to an empty Brewfile
+	unlisted := brewfile.ComputeDrift(nil, installed).Unlisted
+
+	var taps []brewfile.Entry
+	for _, e := range unlisted {
+		if e.Kind == "tap" {
+			taps = append(taps, e)
+		}
+	}
+	candidates := packagesForEntries(packages, unlisted)
+
+	if len(candidates) == 0 && len(taps) == 0 {
+		fmt.Println("👋 Nothing installed to import")
+		return nil
+	}
+
+	skipped, err := ui.ShowExclusionSelector(candidates, map[string]bool{}, a.uiOptions())
+	if err != nil {
+		if errors.Is(err, ui.ErrCancelled) {
+			fmt.Println("👋 Import cancelled")
+			return nil
+		}
+		return fmt.Errorf("error in package selector: %w", err)
+	}
+
+	kept := withoutPackages(candidates, skipped)
+
+	entries := taps
+	for _, pkg := range kept {
+		entries = append(entries, brewfile.EntryFor(pkg))
+	}
+
+	fmt.Printf("\n📝 Importing %d taps and %d packages into %s\n", len(taps), len(kept), a.brewfile.Path())
+	if len(skipped) > 0 {
+		names := make([]string, len(skipped))
+		for i, pkg := range skipped {
+			names[i] = pkg.Token
+		}
+		fmt.Printf("⏭️  Skipping %d: %s\n", len(skipped), strings.Join(names, ", "))
+	}
+
+	if !yes && !a.dryRun && !confirm("Write Brewfile?") {
+		fmt.Println("👋 Import cancelled")
+		return nil
+	}
+
+	if err := a.brewfile.WriteEntries(entries); err != nil {
+		return fmt.Errorf("failed to write Brewfile: %w", err)
+	}
+
+	fmt.Println("✨ Done!")
+	return nil
+}
+
+// withoutPackages returns packages that are not in skip
+func withoutPackages(packages, skip []api.Package) []api.Package {
+	skipped := make(map[string]bool, len(skip))
+	for _, pkg := range skip {
+		skipped[pkg.Type+":"+pkg.Token] = true
+	}
+
+	var result []api.Package
+	for _, pkg := range packages {
+		if !skipped[pkg.Type+":"+pkg.Token] {
+			result = append(result, pkg)
+		}
+	}
+	return result
+}
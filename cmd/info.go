@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/ui"
+)
+
+// packageInfo is the JSON form of the info command
+type packageInfo struct {
+	api.Package
+	InBrewfile     bool           `json:"in_brewfile"`
+	InstallCommand string         `json:"install_command"`
+	Analytics      *api.Analytics `json:"analytics,omitempty"`
+}
+
+var infoCommand = &command{
+	name:    "info",
+	args:    "<package>...",
+	summary: "Show cached details of packages",
+	flags: func(fs *flag.FlagSet) func(a *app, args []string) error {
+		jsonOut := fs.Bool("json", false, "Print details as JSON")
+		markdown := fs.Bool("markdown", false, "Print details as Markdown")
+		pkgType := fs.String("type", "", "Only consider packages of this type (formula or cask)")
+		fetchAnalytics := fs.Bool("analytics", false, "Download install analytics that aren't cached or are stale")
+
+		return func(a *app, args []string) error {
+			return runInfo(a, args, *pkgType, *jsonOut, *markdown, *fetchAnalytics)
+		}
+	},
+}
+
+// runInfo prints the cached details of packages. Install analytics come from
+// the cache unless fetchAnalytics asks for a download, so info stays instant
+// and works offline.
+func runInfo(a *app, names []string, pkgType string, jsonOut, markdown, fetchAnalytics bool) error {
+	if len(names) == 0 {
+		return usageErrorf("info needs at least one package name")
+	}
+	if jsonOut && markdown {
+		return usageErrorf("-json and -markdown cannot be used together")
+	}
+	if err := checkType(pkgType); err != nil {
+		return err
+	}
+
+	existing, err := a.loadExisting()
+	if err != nil {
+		existing = make(map[string]bool)
+	}
+
+	packages, err := a.allPackages()
+	if err != nil {
+		return fmt.Errorf("failed to fetch packages: %w", err)
+	}
+
+	resolver := api.NewResolver(packages)
+	var found []api.Package
+	for _, name := range names {
+		res := resolver.Resolve(name, pkgType)
+		if len(res.Packages) == 0 {
+			return fmt.Errorf("no package named %q%s", name, didYouMean(res))
+		}
+		reportResolution(a, res)
+		found = append(found, res.Packages...)
+	}
+
+	analytics := make([]*api.Analytics, len(found))
+	for i, pkg := range found {
+		detail, err := a.api.CachedDetail(pkg)
+		if fetchAnalytics {
+			detail, err = a.api.FetchDetail(pkg)
+		}
+		if err != nil {
+			a.debugf("No analytics for %s: %v", pkg.Token, err)
+			continue
+		}
+		analytics[i] = &detail.Analytics
+	}
+
+	switch {
+	case jsonOut:
+		infos := make([]packageInfo, len(found))
+		for i, pkg := range found {
+			infos[i] = packageInfo{
+				Package:        pkg,
+				InBrewfile:     existing[pkg.Token],
+				InstallCommand: pkg.InstallCommand(),
+				Analytics:      analytics[i],
+			}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(infos)
+	case markdown:
+		for i, pkg := range found {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Print(markdownInfo(pkg, existing[pkg.Token], analytics[i]))
+		}
+	default:
+		opts := a.uiOptions()
+		opts.Similar = a.similarity(packages).Similar
+		for i, pkg := range found {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Print(ui.PackageDetails(pkg, existing[pkg.Token], 80, opts))
+			if analytics[i] != nil {
+				fmt.Print("\n" + ui.InstallsLine(*analytics[i], opts))
+			}
+		}
+	}
+	return nil
+}
+
+func filterType(packages []api.Package, pkgType string) []api.Package {
+	var result []api.Package
+	for _, pkg := range packages {
+		if pkg.Type == pkgType {
+			result = append(result, pkg)
+		}
+	}
+	return result
+}
+
+// markdownInfo renders package details as a Markdown section
+func markdownInfo(pkg api.Package, inBrewfile bool, analytics *api.Analytics) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "## %s\n\n", pkg.Token)
+	if pkg.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", pkg.Description)
+	}
+
+	row := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "| %s | %s |\n", label, strings.ReplaceAll(value, "|", `\|`))
+		}
+	}
+
+	status := "Not in Brewfile"
+	if inBrewfile {
+		status = "In Brewfile"
+	}
+	typeName := "Formula"
+	if pkg.Type == "cask" {
+		typeName = "Cask"
+	}
+
+	b.WriteString("| Field | Value |\n|---|---|\n")
+	row("Type", typeName)
+	row("Status", status)
+	if pkg.Deprecated {
+		row("Deprecated", "yes")
+	}
+	row("Version", pkg.Version)
+	if pkg.FullName != pkg.Token {
+		row("Full name", pkg.FullName)
+	}
+	row("Tap", pkg.Tap)
+	row("License", pkg.License)
+	row("Aliases", strings.Join(pkg.Aliases, ", "))
+	if pkg.Homepage != "" {
+		row("Homepage", fmt.Sprintf("<%s>", pkg.Homepage))
+	}
+	row("Dependencies", strings.Join(pkg.Dependencies, ", "))
+	if analytics != nil {
+		row("Installs", fmt.Sprintf("%s (30d), %s (90d), %s (365d)",
+			ui.FormatCount(analytics.Installs30d), ui.FormatCount(analytics.Installs90d), ui.FormatCount(analytics.Installs365d)))
+	}
+
+	if pkg.Caveats != "" {
+		fmt.Fprintf(&b, "\n### Caveats\n\n```\n%s\n```\n", strings.TrimRight(pkg.Caveats, "\n"))
+	}
+
+	fmt.Fprintf(&b, "\n### Install\n\n```sh\n%s\n```\n", pkg.InstallCommand())
+
+	return b.String()
+}
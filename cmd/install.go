@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/brew"
+)
+
+// installImmediately installs packages with brew install, bypassing the
+// Brewfile. It returns an error if any package failed.
+func installImmediately(a *app, packages []api.Package, fallback bool) error {
+	var formulae, casks []string
+	for _, pkg := range packages {
+		if pkg.Type == "cask" {
+			casks = append(casks, pkg.Token)
+		} else {
+			formulae = append(formulae, pkg.Token)
+		}
+	}
+
+	if a.dryRun {
+		fmt.Printf("🚀 Would install %d packages directly:\n", len(packages))
+	} else {
+		fmt.Printf("🚀 Installing %d packages directly...\n", len(packages))
+	}
+
+	installer := &brew.Installer{Runner: a.runner, Fallback: fallback}
+	results := installer.Install(formulae, casks)
+
+	if a.dryRun {
+		return nil
+	}
+
+	failed := printInstallSummary(results)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d packages failed to install", failed, len(results))
+	}
+
+	fmt.Println("✨ Done!")
+	return nil
+}
+
+// printInstallSummary prints a table of install results and returns the
+// number of failures
+func printInstallSummary(results []brew.InstallResult) int {
+	fmt.Println("\n📋 Summary")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "   PACKAGE\tTYPE\tSTATUS")
+
+	failed := 0
+	for _, r := range results {
+		kind := "formula"
+		if r.Cask {
+			kind = "cask"
+		}
+
+		var status string
+		switch r.Status {
+		case brew.StatusInstalled:
+			status = "✅ installed"
+		case brew.StatusAlreadyInstalled:
+			status = "☑️  already installed"
+		default:
+			status = "❌ failed"
+			failed++
+		}
+
+		fmt.Fprintf(w, "   %s\t%s\t%s\n", r.Name, kind, status)
+	}
+	w.Flush()
+
+	return failed
+}
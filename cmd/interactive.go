@@ -0,0 +1,265 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/search"
+	"github.com/user/go-brew-search/internal/ui"
+)
+
+// similarLimit is how many packages -similar lists besides the one given
+const similarLimit = 50
+
+// interactiveOptions are the root flags of the interactive mode
+type interactiveOptions struct {
+	immediate bool
+	fallback  bool
+	filter    string
+	discover  string
+	similar   string
+}
+
+// runInteractive is the default mode: search, select, then update the
+// Brewfile or install directly. A filter query narrows the list first; a
+// discover query lists packages by relevance instead, and -similar lists the
+// packages most like one.
+func runInteractive(a *app, opts interactiveOptions) error {
+	q, err := search.ParseQuery(opts.filter)
+	if err != nil {
+		return usageErrorf("invalid -filter: %v", err)
+	}
+	dq, err := search.ParseQuery(opts.discover)
+	if err != nil {
+		return usageErrorf("invalid -discover: %v", err)
+	}
+	if !dq.Empty() && opts.similar != "" {
+		return usageErrorf("-discover and -similar cannot be used together")
+	}
+
+	// Load existing Brewfile packages
+	existing, err := a.loadExisting()
+	if err != nil {
+		a.infof("⚠️  Warning: Could not load Brewfile: %v", err)
+		existing = make(map[string]bool)
+	}
+
+	uiOpts := a.uiOptions()
+	uiOpts.Actions = selectorActions(a, existing, opts.fallback)
+
+	// Qualifiers narrow the list; free text becomes the initial fuzzy query
+	uiOpts.Query = q.Text()
+
+	// -similar and -discover need every package before they can list any;
+	// otherwise the selector opens right away and packages stream in
+	var selected []api.Package
+	if opts.similar == "" && dq.Empty() {
+		selected, err = selectStreaming(a, q, opts.filter, existing, uiOpts)
+	} else {
+		selected, err = selectLoaded(a, q, dq, opts, existing, uiOpts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(selected) == 0 {
+		fmt.Println("👋 No packages selected")
+		return nil
+	}
+
+	if opts.immediate {
+		// Immediate mode: install directly without Brewfile
+		return installImmediately(a, selected, opts.fallback)
+	}
+
+	return addToBrewfile(a, selected, existing, true)
+}
+
+// selectStreaming opens the selector before the packages are loaded and
+// adds them as each source finishes, narrowed by the filter query. Similar
+// packages are listed once everything has loaded.
+func selectStreaming(a *app, q search.Query, filter string, existing map[string]bool, uiOpts ui.Options) ([]api.Package, error) {
+	uiOpts.Filter = strings.TrimSpace(filter)
+
+	// The selector's actions change existing while packages load
+	listed := maps.Clone(existing)
+
+	var mu sync.Mutex
+	var all []api.Package
+	var loadErr error
+
+	similar := newSimilarFinder()
+	uiOpts.Similar, uiOpts.SimilarReady = similar.Similar, similar.Ready()
+	uiOpts.Load = func(add func([]api.Package)) error {
+		err := a.streamPackages(func(packages []api.Package) {
+			mu.Lock()
+			all = append(all, packages...)
+			mu.Unlock()
+			add(q.Filter(packages, listed))
+		})
+
+		mu.Lock()
+		defer mu.Unlock()
+		similar.build(a, all)
+		loadErr = err
+		return err
+	}
+
+	selected, err := ui.ShowPackageSelector(nil, existing, uiOpts)
+	if err != nil {
+		return nil, fmt.Errorf("error in package selector: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if loadErr != nil {
+		if len(selected) == 0 {
+			return nil, fmt.Errorf("failed to fetch packages: %w", loadErr)
+		}
+		a.infof("⚠️  Warning: %v", loadErr)
+	}
+	return selected, nil
+}
+
+// selectLoaded loads every package, lists those similar to -similar or
+// relevant to -discover, and opens the selector
+func selectLoaded(a *app, q, dq search.Query, opts interactiveOptions, existing map[string]bool, uiOpts ui.Options) ([]api.Package, error) {
+	// Fetch packages
+	a.infof("🔄 Fetching Homebrew packages...")
+	packages, err := a.loadPackages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch packages: %w", err)
+	}
+
+	a.infof("✅ Loaded %d packages", len(packages))
+
+	all := packages
+	similar := a.similarity(all)
+	uiOpts.Similar, uiOpts.SimilarReady = similar.Similar, similar.Ready()
+
+	if opts.similar != "" {
+		pkg, err := resolveOne(a, api.NewResolver(all), opts.similar, "")
+		if err != nil {
+			return nil, err
+		}
+		packages = append([]api.Package{pkg}, similar.Similar(pkg, similarLimit)...)
+		uiOpts.Sort = ui.SortRelevance
+		uiOpts.Filter = "similar to " + pkg.Token
+		a.infof("🧭 %d packages similar to %s", len(packages)-1, pkg.Token)
+	}
+
+	if !q.Empty() {
+		packages = q.Filter(packages, existing)
+		uiOpts.Filter = strings.TrimSpace(uiOpts.Filter + " " + opts.filter)
+		a.infof("🔎 %d packages match the filter", len(packages))
+	}
+
+	if !dq.Empty() {
+		results := search.Discover(a.searchIndex(all), packages, dq, search.Options{
+			Limit:    discoverLimit,
+			Existing: existing,
+		})
+		packages = resultPackages(results)
+		uiOpts.Sort = ui.SortRelevance
+		uiOpts.Filter = strings.TrimSpace(opts.filter + " " + opts.discover)
+		a.infof("🔎 %d relevant packages", len(packages))
+	}
+
+	// Show interactive UI
+	selected, err := ui.ShowPackageSelector(packages, existing, uiOpts)
+	if err != nil {
+		return nil, fmt.Errorf("error in package selector: %w", err)
+	}
+	return selected, nil
+}
+
+// selectorActions lets the selector change the Brewfile and install
+// packages without leaving it. The Brewfile actions are unavailable in
+// dry-run mode, as their diff would garble the screen.
+func selectorActions(a *app, existing map[string]bool, fallback bool) ui.Actions {
+	actions := ui.Actions{
+		Install: func(packages []api.Package) error {
+			return installImmediately(a, packages, fallback)
+		},
+	}
+	if a.dryRun {
+		return actions
+	}
+
+	actions.Add = func(packages []api.Package) (string, error) {
+		var newPackages []api.Package
+		for _, pkg := range packages {
+			if !existing[pkg.Token] {
+				newPackages = append(newPackages, pkg)
+			}
+		}
+		if len(newPackages) == 0 {
+			return "✅ Already in the Brewfile", nil
+		}
+		if err := a.brewfile.AddPackages(newPackages); err != nil {
+			return "", fmt.Errorf("failed to update Brewfile: %w", err)
+		}
+		return fmt.Sprintf("📝 Added %d packages to the Brewfile", len(newPackages)), nil
+	}
+	actions.Remove = func(packages []api.Package) (string, error) {
+		names := make([]string, len(packages))
+		for i, pkg := range packages {
+			names[i] = pkg.Token
+		}
+		removed, err := a.brewfile.RemovePackages(names)
+		if err != nil {
+			return "", fmt.Errorf("failed to update Brewfile: %w", err)
+		}
+		if len(removed) == 0 {
+			return "", errors.New("not in the Brewfile")
+		}
+		return fmt.Sprintf("🗑️  Removed %d packages from the Brewfile", len(removed)), nil
+	}
+	return actions
+}
+
+// addToBrewfile adds the packages that aren't listed yet to the Brewfile and
+// optionally runs brew bundle
+func addToBrewfile(a *app, selected []api.Package, existing map[string]bool, bundle bool) error {
+	// Filter out already installed packages
+	newPackages := []api.Package{}
+	for _, pkg := range selected {
+		if !existing[pkg.Token] {
+			newPackages = append(newPackages, pkg)
+		}
+	}
+
+	if len(newPackages) == 0 {
+		fmt.Println("✅ All selected packages are already in Brewfile")
+		return nil
+	}
+
+	// Add new packages to Brewfile
+	if a.dryRun {
+		fmt.Printf("📝 Would add %d new packages to Brewfile:\n", len(newPackages))
+	} else {
+		fmt.Printf("📝 Adding %d new packages to Brewfile...\n", len(newPackages))
+	}
+	if err := a.brewfile.AddPackages(newPackages); err != nil {
+		return fmt.Errorf("failed to update Brewfile: %w", err)
+	}
+
+	if bundle {
+		// Run brew bundle
+		if a.dryRun {
+			fmt.Println("🚀 Would run:")
+		} else {
+			fmt.Println("🚀 Running brew bundle...")
+		}
+		if err := a.brewfile.RunBundle(); err != nil {
+			return fmt.Errorf("failed to run brew bundle: %w", err)
+		}
+	}
+
+	fmt.Println("✨ Done!")
+	return nil
+}
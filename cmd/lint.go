@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/user/go-brew-search/internal/brewfile"
+)
+
+var lintCommand = &command{
+	name:    "lint",
+	summary: "Check the Brewfile for unknown, duplicate and deprecated packages",
+	flags: func(fs *flag.FlagSet) func(a *app, args []string) error {
+		return func(a *app, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("lint takes no arguments")
+			}
+			return runLint(a)
+		}
+	},
+}
+
+// runLint reports problems in the Brewfile and fails if there are any
+func runLint(a *app) error {
+	entries, err := a.brewfile.Entries()
+	if err != nil {
+		return fmt.Errorf("failed to load Brewfile: %w", err)
+	}
+
+	packages, err := a.allPackages()
+	if err != nil {
+		return fmt.Errorf("failed to fetch packages: %w", err)
+	}
+
+	issues := brewfile.Lint(entries, packages)
+	for _, issue := range issues {
+		fmt.Printf("%s:%d: %s\n", a.brewfile.Path(), issue.Entry.Line, issue.Message)
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("%d problems found", len(issues))
+	}
+
+	a.infof("✅ No problems found in %d entries", len(entries))
+	return nil
+}
@@ -1,18 +1,20 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"log"
 	"os"
-	"os/exec"
-	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/brew"
 	"github.com/user/go-brew-search/internal/brewfile"
 	"github.com/user/go-brew-search/internal/cache"
-	"github.com/user/go-brew-search/internal/ui"
+	"github.com/user/go-brew-search/internal/config"
+	"github.com/user/go-brew-search/internal/search"
 )
 
 var (
@@ -21,115 +23,305 @@ var (
 	date    = "unknown"
 )
 
+// Exit codes shared by every command
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// globalOptions are the flags accepted by every command
+type globalOptions struct {
+	config   *config.Loaded
+	brewfile string
+	cacheDir string
+	shared   string
+	ttl      time.Duration
+	brewPath string
+	offline  bool
+	snapshot string
+	dryRun   bool
+	verbose  bool
+	quiet    bool
+}
+
+// newGlobalOptions uses the configuration as the flag defaults
+func newGlobalOptions(cfg *config.Loaded) *globalOptions {
+	g := &globalOptions{
+		config:   cfg,
+		cacheDir: cfg.CacheDir,
+		shared:   cfg.SharedCacheDir,
+		ttl:      cfg.CacheTTL,
+		brewPath: brewPathDefault(),
+		offline:  cfg.Offline,
+		snapshot: strings.Join(cfg.Snapshot, string(os.PathListSeparator)),
+	}
+
+	if len(cfg.Brewfiles) > 0 {
+		g.brewfile = cfg.Brewfiles[0]
+	}
+
+	return g
+}
+
+// register adds the global flags to fs. The current values are used as
+// defaults, so flags given before the command carry over.
+func (g *globalOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.brewfile, "brewfile", g.brewfile, "Path to the Brewfile")
+	fs.StringVar(&g.cacheDir, "cache-dir", g.cacheDir, "Directory for cached package data")
+	fs.StringVar(&g.shared, "shared-cache-dir", g.shared, "Read-only cache directory used when the own cache has no fresh data")
+	fs.DurationVar(&g.ttl, "ttl", g.ttl, "How long cached package data stays fresh")
+	fs.StringVar(&g.brewPath, "brew", g.brewPath, "Path to the brew binary")
+	fs.BoolVar(&g.offline, "offline", g.offline, "Never use the network; use cached data even if expired")
+	fs.StringVar(&g.snapshot, "snapshot", g.snapshot, "formula.json / cask.json files to use instead of the API (implies -offline)")
+	fs.BoolVar(&g.dryRun, "dry-run", g.dryRun, "Show Brewfile changes and brew commands without running them")
+	fs.BoolVar(&g.verbose, "v", g.verbose, "Print more detail about what is happening")
+	fs.BoolVar(&g.quiet, "q", g.quiet, "Only print results and errors")
+}
+
+// record notes the global flags given on the command line in the
+// configuration, so it shows where each effective value came from
+func (g *globalOptions) record(fs *flag.FlagSet) error {
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		if err != nil {
+			return
+		}
+		switch f.Name {
+		case "brewfile":
+			paths := append([]string{g.brewfile}, g.config.Brewfiles[min(1, len(g.config.Brewfiles)):]...)
+			err = g.config.Set("brewfiles", strings.Join(paths, string(os.PathListSeparator)), config.SourceFlag, "-brewfile")
+		case "cache-dir":
+			err = g.config.Set("cache_dir", g.cacheDir, config.SourceFlag, "-cache-dir")
+		case "shared-cache-dir":
+			err = g.config.Set("shared_cache_dir", g.shared, config.SourceFlag, "-shared-cache-dir")
+		case "ttl":
+			err = g.config.Set("cache_ttl", g.ttl.String(), config.SourceFlag, "-ttl")
+		case "offline":
+			err = g.config.Set("offline", strconv.FormatBool(g.offline), config.SourceFlag, "-offline")
+		case "snapshot":
+			err = g.config.Set("snapshot", g.snapshot, config.SourceFlag, "-snapshot")
+		}
+	})
+	return err
+}
+
+// brewPathDefault returns the brew binary from $HOMEBREW_BREW_FILE, which
+// Homebrew sets for its own subprocesses, falling back to brew on PATH
+func brewPathDefault() string {
+	if path := os.Getenv("HOMEBREW_BREW_FILE"); path != "" {
+		return path
+	}
+	return brew.DefaultPath
+}
+
+// app holds the components shared by every command
+type app struct {
+	api      *api.Client
+	brewfile *brewfile.Manager
+	cache    *cache.FSStore
+	runner   brew.Runner
+	opts     *globalOptions
+	config   *config.Loaded
+	dryRun   bool
+	pruning  func() // waits for background pruning
+}
+
+func newApp(g *globalOptions) (*app, error) {
+	if g.brewfile == "" || g.cacheDir == "" {
+		return nil, fmt.Errorf("failed to find the home or cache directory; set -brewfile and -cache-dir")
+	}
+
+	// Initialize cache directory
+	if err := os.MkdirAll(g.cacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Initialize components
+	store := cache.NewFS(g.cacheDir)
+	if g.shared != "" {
+		store.SetShared(g.shared)
+	}
+
+	policy := api.DefaultPolicy()
+	policy.TTL = g.ttl
+	// The search index is rebuilt when the packages change, not by age
+	policy.TTLs[search.IndexKey] = 0
+	for pattern, ttl := range g.config.CacheTTLs {
+		policy.TTLs[pattern] = ttl
+	}
+	if g.config.CacheMaxSize > 0 {
+		policy.MaxSize = g.config.CacheMaxSize
+	}
+
+	apiClient := api.New(store)
+	apiClient.SetPolicy(policy)
+	apiClient.SetHistorySize(g.config.HistorySize)
+	apiClient.SetBaseURL(g.config.APIBaseURL)
+	apiClient.SetOffline(g.offline)
+	if len(g.config.Snapshot) > 0 {
+		if err := apiClient.LoadSnapshot(g.config.Snapshot...); err != nil {
+			return nil, err
+		}
+	}
+
+	var runner brew.Runner = brew.NewExecRunner(g.brewPath)
+	if g.dryRun {
+		// Queries still run so reports reflect the real state
+		runner = brew.NewDryRunner(g.brewPath, runner)
+	}
+
+	brewfileManager := brewfile.New(g.brewfile, runner)
+	if g.dryRun {
+		brewfileManager.SetDryRun(os.Stdout)
+	}
+
+	return &app{
+		api:      apiClient,
+		brewfile: brewfileManager,
+		cache:    store,
+		runner:   runner,
+		opts:     g,
+		config:   g.config,
+		dryRun:   g.dryRun,
+		pruning:  func() {},
+	}, nil
+}
+
+// infof prints a progress message unless -q was given
+func (a *app) infof(format string, args ...any) {
+	if !a.opts.quiet {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+}
+
+// debugf prints a message only when -v was given
+func (a *app) debugf(format string, args ...any) {
+	if a.opts.verbose {
+		fmt.Fprintf(os.Stderr, "🔎 "+format+"\n", args...)
+	}
+}
+
 func main() {
-	// Parse command line flags
-	immediateMode := flag.Bool("immediate", false, "Install packages immediately without updating Brewfile")
-	versionFlag := flag.Bool("version", false, "Show version information")
-	flag.Parse()
+	os.Exit(run(os.Args[1:]))
+}
+
+// run parses the command line, runs the command and returns the exit code
+func run(args []string) int {
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		return exitFailure
+	}
+	g := newGlobalOptions(cfg)
+
+	root := flag.NewFlagSet("brew-search", flag.ContinueOnError)
+	g.register(root)
+	var opts interactiveOptions
+	root.BoolVar(&opts.immediate, "immediate", false, "Install packages immediately without updating Brewfile")
+	root.BoolVar(&opts.fallback, "fallback", false, "In immediate mode, retry packages one by one when a batch install fails")
+	root.StringVar(&opts.filter, "filter", "", "Only list packages matching this query, e.g. 'type:cask license:MIT'")
+	root.StringVar(&opts.discover, "discover", "", "List packages by relevance to this description, e.g. 'markdown to pdf'")
+	root.StringVar(&opts.similar, "similar", "", "List packages similar to this one")
+	versionFlag := root.Bool("version", false, "Show version information")
+	root.Usage = func() { printUsage(root) }
+
+	if err := root.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if err := g.record(root); err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		return exitUsage
+	}
 
 	// Handle version flag
 	if *versionFlag {
 		fmt.Printf("🍺 go-brew-search %s\n", version)
 		fmt.Printf("📅 Built: %s\n", date)
 		fmt.Printf("🔨 Commit: %s\n", commit)
-		os.Exit(0)
-	}
-	// Initialize cache directory
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		log.Fatal("❌ Failed to get home directory:", err)
+		return exitOK
 	}
 
-	cacheDir := filepath.Join(homeDir, ".cache", "go-brew-search")
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
-		log.Fatal("❌ Failed to create cache directory:", err)
+	// Running without a command keeps the interactive search
+	if root.NArg() == 0 {
+		explicit := false
+		root.Visit(func(f *flag.Flag) {
+			explicit = explicit || f.Name == "immediate"
+		})
+		if !explicit {
+			opts.immediate = cfg.DefaultMode == config.ModeImmediate
+		}
+
+		return runApp(g, func(a *app) error {
+			return runInteractive(a, opts)
+		})
 	}
 
-	// Initialize components
-	cacheManager := cache.New(cacheDir, 24*time.Hour)
-	apiClient := api.New(cacheManager)
-	brewfileManager := brewfile.New(filepath.Join(homeDir, "Brewfile"))
+	name := root.Arg(0)
+	if name == "help" {
+		return runHelp(g, root, root.Args()[1:])
+	}
 
-	// Load existing Brewfile packages
-	existing, err := brewfileManager.LoadExisting()
-	if err != nil {
-		log.Printf("⚠️  Warning: Could not load Brewfile: %v", err)
-		existing = make(map[string]bool)
+	cmd := findCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "❌ Unknown command: %s\n", name)
+		fmt.Fprintln(os.Stderr, "Run 'brew-search help' for a list of commands.")
+		return exitUsage
 	}
 
-	// Fetch packages
-	fmt.Println("🔄 Fetching Homebrew packages...")
-	packages, err := apiClient.FetchAllPackages()
+	fs := cmd.flagSet()
+	g.register(fs)
+
+	positional, err := parseInterspersed(fs, root.Args()[1:])
 	if err != nil {
-		log.Fatal("❌ Failed to fetch packages:", err)
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if err := g.record(fs); err != nil {
+		fmt.Fprintln(os.Stderr, "❌", err)
+		return exitUsage
 	}
 
-	fmt.Printf("✅ Loaded %d packages\n", len(packages))
+	return runApp(g, func(a *app) error {
+		return cmd.run(a, positional)
+	})
+}
 
-	// Show interactive UI
-	selected, err := ui.ShowPackageSelector(packages, existing)
+// runApp sets up the shared components and runs fn, turning its error into
+// an exit code
+func runApp(g *globalOptions, fn func(a *app) error) int {
+	a, err := newApp(g)
 	if err != nil {
-		log.Fatal("❌ Error in package selector:", err)
-	}
-
-	if len(selected) == 0 {
-		fmt.Println("👋 No packages selected")
-		return
-	}
-
-	if *immediateMode {
-		// Immediate mode: install directly without Brewfile
-		fmt.Printf("🚀 Installing %d packages directly...\n", len(selected))
-		
-		for _, pkg := range selected {
-			fmt.Printf("📦 Installing %s...\n", pkg.Token)
-			
-			var cmd *exec.Cmd
-			if pkg.Type == "cask" {
-				cmd = exec.Command("brew", "install", "--cask", pkg.Token)
-			} else {
-				cmd = exec.Command("brew", "install", pkg.Token)
-			}
-			
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			
-			if err := cmd.Run(); err != nil {
-				log.Printf("⚠️  Failed to install %s: %v", pkg.Token, err)
-				continue
-			}
-			
-			fmt.Printf("✅ Installed %s\n", pkg.Token)
-		}
-		
-		fmt.Println("✨ Done!")
-	} else {
-		// Normal mode: update Brewfile
-		// Filter out already installed packages
-		newPackages := []api.Package{}
-		for _, pkg := range selected {
-			if !existing[pkg.Token] {
-				newPackages = append(newPackages, pkg)
-			}
-		}
+		fmt.Fprintln(os.Stderr, "❌", err)
+		return exitFailure
+	}
 
-		if len(newPackages) == 0 {
-			fmt.Println("✅ All selected packages are already in Brewfile")
-			return
-		}
+	if a.dryRun {
+		a.infof("🧪 Dry run: nothing will be written or installed")
+	}
 
-		// Add new packages to Brewfile
-		fmt.Printf("📝 Adding %d new packages to Brewfile...\n", len(newPackages))
-		if err := brewfileManager.AddPackages(newPackages); err != nil {
-			log.Fatal("❌ Failed to update Brewfile:", err)
-		}
+	// Pruning runs alongside the command and only touches entries it
+	// does not need, under the same per-key locks
+	if !a.dryRun && !a.api.Offline() {
+		a.pruning = a.startPrune()
+	}
+	err = fn(a)
+	a.pruning()
 
-		// Run brew bundle
-		fmt.Println("🚀 Running brew bundle...")
-		if err := brewfileManager.RunBundle(); err != nil {
-			log.Fatal("❌ Failed to run brew bundle:", err)
+	if err != nil {
+		var usage *usageError
+		if errors.As(err, &usage) {
+			fmt.Fprintln(os.Stderr, "❌", err)
+			return exitUsage
 		}
-
-		fmt.Println("✨ Done!")
+		fmt.Fprintln(os.Stderr, "❌", err)
+		return exitFailure
 	}
-}
\ No newline at end of file
+
+	return exitOK
+}
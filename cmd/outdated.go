@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/brew"
+	"github.com/user/go-brew-search/internal/ui"
+)
+
+// Outdated report statuses
+const (
+	statusOutdated     = "outdated"
+	statusCurrent      = "current"
+	statusNotInstalled = "not installed"
+	statusUnknown      = "unknown" // not in the package index
+)
+
+// outdatedRow is one Brewfile package in the outdated report
+type outdatedRow struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Installed string `json:"installed,omitempty"`
+	Latest    string `json:"latest,omitempty"`
+	Status    string `json:"status"`
+
+	pkg api.Package
+}
+
+// outdatedOptions are the flags of the outdated command
+type outdatedOptions struct {
+	all         bool
+	json        bool
+	pkgType     string
+	interactive bool
+}
+
+var outdatedCommand = &command{
+	name:    "outdated",
+	summary: "Compare installed versions of Brewfile packages with the latest ones",
+	flags: func(fs *flag.FlagSet) func(a *app, args []string) error {
+		var opts outdatedOptions
+		fs.BoolVar(&opts.all, "all", false, "Also show packages that are up to date, not installed or unknown")
+		fs.BoolVar(&opts.json, "json", false, "Print the report as JSON")
+		fs.StringVar(&opts.pkgType, "type", "", "Only check packages of this type (formula or cask)")
+		fs.BoolVar(&opts.interactive, "interactive", false, "Select outdated packages to upgrade")
+		fs.BoolVar(&opts.interactive, "i", false, "Shorthand for -interactive")
+
+		return func(a *app, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("outdated takes no arguments")
+			}
+			return runOutdated(a, opts)
+		}
+	},
+}
+
+// runOutdated compares the installed versions of Brewfile packages with the
+// latest versions in the package index
+func runOutdated(a *app, opts outdatedOptions) error {
+	if err := checkType(opts.pkgType); err != nil {
+		return err
+	}
+	if opts.json && opts.interactive {
+		return usageErrorf("-json and -interactive cannot be used together")
+	}
+
+	entries, err := a.brewfile.Entries()
+	if err != nil {
+		return fmt.Errorf("failed to load Brewfile: %w", err)
+	}
+
+	a.infof("🔄 Checking installed versions...")
+	formulae, casks, err := brew.InstalledVersions(a.runner)
+	if err != nil {
+		return err
+	}
+
+	packages, err := a.allPackages()
+	if err != nil {
+		return fmt.Errorf("failed to fetch packages: %w", err)
+	}
+	byKey := make(map[string]api.Package, len(packages))
+	for _, pkg := range packages {
+		byKey[pkg.Type+":"+pkg.Token] = pkg
+		if pkg.Type == "formula" && pkg.FullName != "" {
+			byKey[pkg.Type+":"+pkg.FullName] = pkg
+		}
+	}
+
+	var rows, outdated []outdatedRow
+	for _, e := range entries {
+		var pkgType string
+		var installed map[string][]string
+		switch e.Kind {
+		case "brew":
+			pkgType, installed = "formula", formulae
+		case "cask":
+			pkgType, installed = "cask", casks
+		default:
+			continue
+		}
+		if opts.pkgType != "" && opts.pkgType != pkgType {
+			continue
+		}
+
+		row := outdatedRow{Kind: e.Kind, Name: e.Name}
+		row.Installed = brew.NewestVersion(installed[shortName(e.Name)])
+		pkg, known := byKey[pkgType+":"+e.Name]
+		if known {
+			row.pkg = pkg
+			row.Latest = pkg.PkgVersion()
+		}
+
+		switch {
+		case row.Installed == "":
+			row.Status = statusNotInstalled
+		case row.Latest == "":
+			row.Status = statusUnknown
+		case row.Installed == brew.Unversioned || row.Latest == brew.Unversioned:
+			row.Status = statusCurrent
+		case brew.CompareVersions(row.Installed, row.Latest) < 0:
+			row.Status = statusOutdated
+			outdated = append(outdated, row)
+		default:
+			row.Status = statusCurrent
+		}
+
+		if opts.all || row.Status == statusOutdated {
+			rows = append(rows, row)
+		}
+	}
+
+	if opts.json {
+		if rows == nil {
+			rows = []outdatedRow{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	}
+
+	if err := printOutdated(rows, opts.all); err != nil {
+		return err
+	}
+	if len(outdated) == 0 {
+		fmt.Println("✅ Every installed Brewfile package is up to date")
+		return nil
+	}
+	fmt.Printf("\n⬆️  %d outdated packages\n", len(outdated))
+
+	if !opts.interactive {
+		return nil
+	}
+	return upgradeOutdated(a, outdated)
+}
+
+// shortName is the name brew list reports for a package: tap-qualified
+// names are listed by their short name
+func shortName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+func printOutdated(rows []outdatedRow, withStatus bool) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "NAME\tTYPE\tINSTALLED\tLATEST"
+	if withStatus {
+		header += "\tSTATUS"
+	}
+	fmt.Fprintln(w, header)
+
+	for _, row := range rows {
+		line := fmt.Sprintf("%s\t%s\t%s\t%s", row.Name, row.Kind, orDash(row.Installed), orDash(row.Latest))
+		if withStatus {
+			line += "\t" + row.Status
+		}
+		fmt.Fprintln(w, line)
+	}
+	return w.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
+
+// upgradeOutdated lets the user pick outdated packages and upgrades them
+func upgradeOutdated(a *app, outdated []outdatedRow) error {
+	// The selector shows each package's version as installed → latest
+	candidates := make([]api.Package, len(outdated))
+	listed := make(map[string]bool, len(outdated))
+	for i, row := range outdated {
+		pkg := row.pkg
+		pkg.Token = row.Name
+		pkg.Version = row.Installed + " → " + row.Latest
+		pkg.Revision = 0
+		candidates[i] = pkg
+		listed[pkg.Token] = true
+	}
+
+	selected, err := ui.ShowPackageSelector(candidates, listed, a.uiOptions())
+	if err != nil {
+		return fmt.Errorf("error in package selector: %w", err)
+	}
+	if len(selected) == 0 {
+		fmt.Println("👋 No packages selected")
+		return nil
+	}
+
+	var formulae, casks []string
+	for _, pkg := range selected {
+		if pkg.Type == "cask" {
+			casks = append(casks, pkg.Token)
+		} else {
+			formulae = append(formulae, pkg.Token)
+		}
+	}
+
+	if a.dryRun {
+		fmt.Println("🚀 Would run:")
+	} else {
+		fmt.Printf("🚀 Upgrading %d packages...\n", len(selected))
+	}
+	if err := brew.Upgrade(a.runner, formulae, casks); err != nil {
+		return fmt.Errorf("failed to upgrade packages: %w", err)
+	}
+
+	fmt.Println("✨ Done!")
+	return nil
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/brew"
+	"github.com/user/go-brew-search/internal/brewfile"
+	"github.com/user/go-brew-search/internal/search"
+	"github.com/user/go-brew-search/internal/ui"
+)
+
+// loadPackages returns the packages to list, leaving out hidden package
+// types
+func (a *app) loadPackages() ([]api.Package, error) {
+	packages, err := a.allPackages()
+	if err != nil {
+		return nil, err
+	}
+	return a.visible(packages), nil
+}
+
+// streamPackages is loadPackages for a selector that opens before the
+// packages are loaded: it passes them to fn as each source finishes. Nothing
+// is printed, as the selector has the terminal.
+func (a *app) streamPackages(fn func([]api.Package)) error {
+	_, err := a.api.StreamPackages(func(packages []api.Package) {
+		fn(a.visible(packages))
+	})
+	if err != nil {
+		return a.fetchError(err)
+	}
+
+	if len(a.config.ExtraTaps) > 0 {
+		tapPackages, err := a.tapPackages()
+		fn(a.visible(tapPackages))
+		if err != nil {
+			return fmt.Errorf("could not load extra taps: %w", err)
+		}
+	}
+	return nil
+}
+
+// visible leaves out hidden package types. packages may be the API
+// client's own data, so the result is a new slice.
+func (a *app) visible(packages []api.Package) []api.Package {
+	if len(a.config.HiddenTypes) == 0 {
+		return packages
+	}
+
+	visible := make([]api.Package, 0, len(packages))
+	for _, pkg := range packages {
+		if !a.config.Hidden(pkg.Type) {
+			visible = append(visible, pkg)
+		}
+	}
+	return visible
+}
+
+// allPackages returns the cached package index plus packages from the
+// configured extra taps. Commands that look packages up by name use it, so
+// hidden types can still be found.
+func (a *app) allPackages() ([]api.Package, error) {
+	packages, err := a.indexPackages()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(a.config.ExtraTaps) > 0 {
+		tapPackages, err := a.tapPackages()
+		if err != nil {
+			a.infof("⚠️  Warning: Could not load extra taps: %v", err)
+		}
+		packages = append(packages, tapPackages...)
+	}
+
+	return packages, nil
+}
+
+// tapPackages returns the packages of the configured extra taps
+func (a *app) tapPackages() ([]api.Package, error) {
+	tapPackages, err := brew.TapPackages(a.runner, a.config.ExtraTaps)
+	packages := make([]api.Package, len(tapPackages))
+	for i, tp := range tapPackages {
+		packages[i] = tapPackage(tp)
+	}
+	return packages, err
+}
+
+// indexPackages returns the package index from the API or the cache,
+// without packages from extra taps
+func (a *app) indexPackages() ([]api.Package, error) {
+	packages, err := a.api.FetchAllPackages()
+	if err != nil {
+		return nil, a.fetchError(err)
+	}
+	a.reportDataAge()
+	return packages, nil
+}
+
+// fetchError explains how to get package data when there is none offline
+func (a *app) fetchError(err error) error {
+	if errors.Is(err, api.ErrNoData) {
+		return fmt.Errorf("no package data available offline. Run 'brew-search cache refresh' while online, " +
+			"'brew-search cache import <archive>', or pass -snapshot formula.json" + string(os.PathListSeparator) + "cask.json")
+	}
+	return err
+}
+
+// reportDataAge notes how old the package data is when it may be out of
+// date, and which package types had no data offline
+func (a *app) reportDataAge() {
+	if missing := a.api.Missing(); len(missing) == 1 {
+		shown := "formulae"
+		if missing[0] == "formulae" {
+			shown = "casks"
+		}
+		a.infof("⚠️  No %s available offline; showing %s only", missing[0], shown)
+	}
+
+	updated := a.api.Updated()
+	if updated.IsZero() {
+		return
+	}
+
+	age := time.Since(updated)
+	if a.api.Offline() && age > a.opts.ttl {
+		a.infof("📅 Offline: using package data from %s ago", ui.FormatAge(age))
+		return
+	}
+	a.debugf("Package data from %s ago", ui.FormatAge(age))
+}
+
+// tapPackage describes a package from an extra tap. Taps aren't in the API
+// index, so only the name is known.
+func tapPackage(tp brew.TapPackage) api.Package {
+	name := tp.Name
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	pkg := api.Package{
+		Token:    tp.Name,
+		Name:     name,
+		FullName: tp.Name,
+		Tap:      tp.Tap,
+		Type:     "formula",
+	}
+	if tp.Cask {
+		pkg.Type = "cask"
+	}
+	return pkg
+}
+
+// loadExisting returns the packages in the Brewfile and in any additional
+// configured Brewfiles
+func (a *app) loadExisting() (map[string]bool, error) {
+	existing, err := a.brewfile.LoadExisting()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range a.config.Brewfiles[min(1, len(a.config.Brewfiles)):] {
+		extra, err := brewfile.New(path, a.runner).LoadExisting()
+		if err != nil {
+			a.infof("⚠️  Warning: Could not load %s: %v", path, err)
+			continue
+		}
+		for name := range extra {
+			existing[name] = true
+		}
+	}
+
+	return existing, nil
+}
+
+// searchIndex returns the full-text index of packages, from the cache when
+// they haven't changed
+func (a *app) searchIndex(packages []api.Package) *search.Index {
+	start := time.Now()
+	ix := search.CachedIndex(a.cache, packages)
+	a.debugf("Search index ready in %s", time.Since(start).Round(time.Millisecond))
+	return ix
+}
+
+// similarFinder lists the packages most like a package, for the selector
+// and package details. Its model takes a while to build for the whole index,
+// so that happens in the background, and answers are remembered because the
+// preview asks again on every redraw.
+type similarFinder struct {
+	ready chan struct{}
+	model *search.Similarity
+
+	mu      sync.Mutex
+	results map[string]similarResult // by type and token
+}
+
+// similarResult is an answer for the largest limit asked so far
+type similarResult struct {
+	limit    int
+	packages []api.Package
+}
+
+func newSimilarFinder() *similarFinder {
+	return &similarFinder{
+		ready:   make(chan struct{}),
+		results: make(map[string]similarResult),
+	}
+}
+
+// similarity starts preparing packages for finding similar ones in the
+// background
+func (a *app) similarity(packages []api.Package) *similarFinder {
+	f := newSimilarFinder()
+	f.build(a, packages)
+	return f
+}
+
+// build prepares packages for comparison in the background and closes
+// Ready when done. It must be called once.
+func (f *similarFinder) build(a *app, packages []api.Package) {
+	go func() {
+		start := time.Now()
+		f.model = search.NewSimilarity(packages)
+		a.debugf("Similarity ready in %s", time.Since(start).Round(time.Millisecond))
+		close(f.ready)
+	}()
+}
+
+// Ready is closed once Similar can answer without waiting
+func (f *similarFinder) Ready() <-chan struct{} {
+	return f.ready
+}
+
+// Similar returns up to limit packages most like pkg, best first, waiting
+// for the model if it is still being built
+func (f *similarFinder) Similar(pkg api.Package, limit int) []api.Package {
+	<-f.ready
+
+	key := pkg.Type + ":" + pkg.Token
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r, ok := f.results[key]
+	if !ok || r.limit < limit {
+		r = similarResult{limit: limit, packages: resultPackages(f.model.Similar(pkg, limit))}
+		f.results[key] = r
+	}
+	// A shorter list is a prefix of a longer one
+	n := min(limit, len(r.packages))
+	return r.packages[:n:n]
+}
+
+func resultPackages(results []search.Result) []api.Package {
+	packages := make([]api.Package, len(results))
+	for i, r := range results {
+		packages[i] = r.Package
+	}
+	return packages
+}
+
+// uiOptions returns the configured selector settings
+func (a *app) uiOptions() ui.Options {
+	return ui.Options{
+		Sort:  a.config.Sort,
+		Theme: a.config.Theme,
+		Keys:  a.config.Keys,
+
+		Data: a.api,
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/config"
+)
+
+func TestVisibleKeepsInput(t *testing.T) {
+	cfg := &config.Loaded{Config: config.Default()}
+	cfg.HiddenTypes = []string{"cask"}
+	a := &app{config: cfg}
+
+	packages := []api.Package{
+		{Token: "firefox", Type: "cask"},
+		{Token: "jq", Type: "formula"},
+		{Token: "slack", Type: "cask"},
+		{Token: "wget", Type: "formula"},
+	}
+	original := append([]api.Package(nil), packages...)
+
+	visible := a.visible(packages)
+	if want := []api.Package{packages[1], packages[3]}; !reflect.DeepEqual(visible, want) {
+		t.Errorf("visible = %+v, want %+v", visible, want)
+	}
+	if !reflect.DeepEqual(packages, original) {
+		t.Errorf("visible changed its input to %+v", packages)
+	}
+}
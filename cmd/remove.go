@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+var removeCommand = &command{
+	name:    "remove",
+	args:    "<package>...",
+	summary: "Remove packages from the Brewfile",
+	flags: func(fs *flag.FlagSet) func(a *app, args []string) error {
+		uninstall := fs.Bool("uninstall", false, "Also uninstall the removed formulae and casks")
+
+		return func(a *app, args []string) error {
+			return runRemove(a, args, *uninstall)
+		}
+	},
+}
+
+// runRemove removes entries from the Brewfile and optionally uninstalls them
+func runRemove(a *app, names []string, uninstall bool) error {
+	if len(names) == 0 {
+		return usageErrorf("remove needs at least one package name")
+	}
+
+	removed, err := a.brewfile.RemovePackages(names)
+	if err != nil {
+		return fmt.Errorf("failed to update Brewfile: %w", err)
+	}
+	if len(removed) == 0 {
+		return fmt.Errorf("none of %s are in %s", strings.Join(names, ", "), a.brewfile.Path())
+	}
+
+	found := make(map[string]bool, len(removed))
+	for _, e := range removed {
+		found[strings.ToLower(e.Name)] = true
+		fmt.Printf("🗑️  Removed %s \"%s\" (line %d)\n", e.Kind, e.Name, e.Line)
+	}
+	for _, name := range names {
+		if !found[strings.ToLower(name)] {
+			a.infof("⚠️  %s is not in the Brewfile", name)
+		}
+	}
+
+	if !uninstall {
+		return nil
+	}
+
+	var failed int
+	uninstalled := make(map[string]bool, len(removed))
+	for _, e := range removed {
+		key := e.Kind + ":" + strings.ToLower(e.Name)
+		if uninstalled[key] {
+			continue
+		}
+		uninstalled[key] = true
+
+		var err error
+		switch e.Kind {
+		case "brew":
+			err = a.runner.Run("uninstall", e.Name)
+		case "cask":
+			err = a.runner.Run("uninstall", "--cask", e.Name)
+		default:
+			continue
+		}
+		if err != nil {
+			a.infof("⚠️  Failed to uninstall %s: %v", e.Name, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d packages failed to uninstall", failed)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/search"
+	"github.com/user/go-brew-search/internal/text"
+)
+
+// searchResult is the JSON form of a search result
+type searchResult struct {
+	Token       string `json:"token"`
+	Type        string `json:"type"`
+	FullName    string `json:"full_name,omitempty"`
+	Version     string `json:"version,omitempty"`
+	Description string `json:"desc,omitempty"`
+	Homepage    string `json:"homepage,omitempty"`
+	InBrewfile  bool   `json:"in_brewfile"`
+	Score       int    `json:"score"`
+}
+
+// searchOptions are the flags of the search command
+type searchOptions struct {
+	json     bool
+	tsv      bool
+	limit    int
+	pkgType  string
+	exact    bool
+	discover bool
+}
+
+// discoverLimit is how many relevant packages the interactive discover
+// mode lists
+const discoverLimit = 100
+
+var searchCommand = &command{
+	name:    "search",
+	args:    "<query>",
+	summary: "Search the package cache without the interactive UI",
+	flags: func(fs *flag.FlagSet) func(a *app, args []string) error {
+		var opts searchOptions
+		fs.BoolVar(&opts.json, "json", false, "Print results as JSON")
+		fs.BoolVar(&opts.tsv, "tsv", false, "Print results as tab-separated values")
+		fs.IntVar(&opts.limit, "limit", 20, "Maximum number of results (0 for all)")
+		fs.StringVar(&opts.pkgType, "type", "", "Only show packages of this type (formula or cask)")
+		fs.BoolVar(&opts.exact, "exact", false, "Match query terms as substrings, without fuzzy matching")
+		fs.BoolVar(&opts.discover, "discover", false, "Rank by relevance of names and full descriptions rather than name matches")
+
+		return func(a *app, args []string) error {
+			return runSearch(a, strings.Join(args, " "), opts)
+		}
+	},
+}
+
+// runSearch prints packages matching a query without the interactive UI
+func runSearch(a *app, query string, opts searchOptions) error {
+	if err := checkType(opts.pkgType); err != nil {
+		return err
+	}
+	if opts.json && opts.tsv {
+		return usageErrorf("-json and -tsv cannot be used together")
+	}
+	q, err := search.ParseQuery(query)
+	if err != nil {
+		return usageErrorf("invalid query: %v", err)
+	}
+
+	existing, err := a.loadExisting()
+	if err != nil {
+		existing = make(map[string]bool)
+	}
+
+	packages, err := a.loadPackages()
+	if err != nil {
+		return fmt.Errorf("failed to fetch packages: %w", err)
+	}
+
+	searchOpts := search.Options{
+		Type:     opts.pkgType,
+		Exact:    opts.exact,
+		Limit:    opts.limit,
+		Existing: existing,
+	}
+	var results []search.Result
+	if opts.discover {
+		results = search.Discover(a.searchIndex(packages), packages, q, searchOpts)
+	} else {
+		results = search.Rank(packages, q, searchOpts)
+	}
+
+	switch {
+	case opts.json:
+		return printSearchJSON(results, existing)
+	case opts.tsv:
+		printSearchTSV(results)
+	default:
+		printSearchTable(results, existing)
+	}
+	return nil
+}
+
+// checkType validates a -type flag value
+func checkType(pkgType string) error {
+	if pkgType != "" && pkgType != "formula" && pkgType != "cask" {
+		return usageErrorf("invalid -type %q: must be formula or cask", pkgType)
+	}
+	return nil
+}
+
+func printSearchJSON(results []search.Result, existing map[string]bool) error {
+	out := make([]searchResult, len(results))
+	for i, r := range results {
+		pkg := r.Package
+		out[i] = searchResult{
+			Token:       pkg.Token,
+			Type:        pkg.Type,
+			FullName:    pkg.FullName,
+			Version:     pkg.Version,
+			Description: pkg.Description,
+			Homepage:    pkg.Homepage,
+			InBrewfile:  existing[pkg.Token],
+			Score:       r.Score,
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func printSearchTSV(results []search.Result) {
+	for _, r := range results {
+		pkg := r.Package
+		fmt.Printf("%s\t%s\t%s\t%s\n", tsvField(pkg.Token), pkg.Type, tsvField(pkg.Version), tsvField(pkg.Description))
+	}
+}
+
+// tsvField keeps a value on one line and in one column
+func tsvField(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+// searchTableSep separates the columns of the search table
+const searchTableSep = "  "
+
+func printSearchTable(results []search.Result, existing map[string]bool) {
+	if len(results) == 0 {
+		fmt.Fprintln(os.Stderr, "🤷 No packages found")
+		return
+	}
+
+	columns := []text.Column{
+		{Natural: text.Width("NAME")},
+		{Natural: text.Width("TYPE")},
+		{Natural: text.Width("VERSION")},
+		{Natural: text.Width("DESCRIPTION"), Max: 60},
+	}
+	for _, r := range results {
+		for i, cell := range searchTableCells(r.Package) {
+			columns[i].Natural = max(columns[i].Natural, text.Width(cell))
+		}
+	}
+	// The table is often piped, so it isn't fitted to the terminal; only
+	// descriptions are capped
+	widths := text.Fit(math.MaxInt, text.Width(searchTableSep), columns)
+
+	printSearchRow("", []string{"NAME", "TYPE", "VERSION", "DESCRIPTION"}, widths)
+	for _, r := range results {
+		status := ""
+		if existing[r.Package.Token] {
+			status = "✅"
+		}
+		printSearchRow(status, searchTableCells(r.Package), widths)
+	}
+}
+
+// searchTableCells returns what the search table shows of a package
+func searchTableCells(pkg api.Package) []string {
+	return []string{pkg.Token, pkg.Type, pkg.Version, pkg.Description}
+}
+
+// printSearchRow prints a row of the search table, laid out by display width
+// so the two-column ✅ marker and wide characters keep the columns aligned
+func printSearchRow(status string, cells []string, widths []int) {
+	var b strings.Builder
+	b.WriteString(text.Pad(status, 2) + " ")
+	for i, cell := range cells {
+		if i == len(cells)-1 {
+			b.WriteString(text.Truncate(cell, widths[i]))
+			break
+		}
+		b.WriteString(text.Pad(cell, widths[i]) + searchTableSep)
+	}
+	fmt.Println(strings.TrimRight(b.String(), " "))
+}
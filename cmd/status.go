@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/brew"
+	"github.com/user/go-brew-search/internal/brewfile"
+	"github.com/user/go-brew-search/internal/ui"
+)
+
+var statusCommand = &command{
+	name:    "status",
+	summary: "Show drift between the Brewfile and installed packages",
+	flags: func(fs *flag.FlagSet) func(a *app, args []string) error {
+		interactive := fs.Bool("interactive", false, "Select unlisted packages to add to the Brewfile")
+		fs.BoolVar(interactive, "i", false, "Shorthand for -interactive")
+
+		return func(a *app, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("status takes no arguments")
+			}
+			return runStatus(a, *interactive)
+		}
+	},
+}
+
+// runStatus shows drift between the Brewfile and the installed packages
+func runStatus(a *app, interactive bool) error {
+	entries, err := a.brewfile.Entries()
+	if err != nil {
+		return fmt.Errorf("failed to load Brewfile: %w", err)
+	}
+
+	a.infof("🔄 Checking installed packages...")
+	installed, err := brew.LoadInstalled(a.runner)
+	if err != nil {
+		return err
+	}
+
+	drift := brewfile.ComputeDrift(entries, installed)
+	printDrift(a.brewfile.Path(), drift)
+
+	if !interactive || len(drift.Unlisted) == 0 {
+		return nil
+	}
+
+	return adoptUnlisted(a, drift.Unlisted)
+}
+
+func printDrift(path string, drift brewfile.Drift) {
+	fmt.Printf("\n📊 Drift for %s\n", path)
+
+	if drift.InSync() {
+		fmt.Println("✅ Brewfile matches installed packages")
+		return
+	}
+
+	if len(drift.Unlisted) > 0 {
+		fmt.Printf("\n➕ Installed but not in Brewfile (%d):\n", len(drift.Unlisted))
+		for _, e := range drift.Unlisted {
+			fmt.Printf("   %s \"%s\"\n", e.Kind, e.Name)
+		}
+	}
+
+	if len(drift.Missing) > 0 {
+		fmt.Printf("\n➖ In Brewfile but not installed (%d):\n", len(drift.Missing))
+		for _, e := range drift.Missing {
+			fmt.Printf("   %s \"%s\"  (line %d)\n", e.Kind, e.Name, e.Line)
+		}
+	}
+}
+
+// adoptUnlisted lets the user pick installed packages to add to the Brewfile
+func adoptUnlisted(a *app, unlisted []brewfile.Entry) error {
+	a.infof("\n🔄 Fetching Homebrew packages...")
+	packages, err := a.allPackages()
+	if err != nil {
+		return fmt.Errorf("failed to fetch packages: %w", err)
+	}
+
+	candidates := packagesForEntries(packages, unlisted)
+	if len(candidates) == 0 {
+		fmt.Println("✅ No unlisted formulae or casks to adopt")
+		return nil
+	}
+
+	selected, err := ui.ShowPackageSelector(candidates, map[string]bool{}, a.uiOptions())
+	if err != nil {
+		return fmt.Errorf("error in package selector: %w", err)
+	}
+
+	if len(selected) == 0 {
+		fmt.Println("👋 No packages selected")
+		return nil
+	}
+
+	fmt.Printf("📝 Adding %d packages to Brewfile...\n", len(selected))
+	if err := a.brewfile.AddPackages(selected); err != nil {
+		return fmt.Errorf("failed to update Brewfile: %w", err)
+	}
+
+	fmt.Println("✨ Done!")
+	return nil
+}
+
+// packagesForEntries looks up formula and cask entries in the package list.
+// Entries that aren't in the API data (e.g. from private taps) still get a
+// minimal package so they can be selected.
+func packagesForEntries(packages []api.Package, entries []brewfile.Entry) []api.Package {
+	byKey := make(map[string]api.Package, len(packages))
+	for _, pkg := range packages {
+		byKey[pkg.Type+":"+pkg.Token] = pkg
+		if pkg.FullName != "" {
+			byKey[pkg.Type+":"+pkg.FullName] = pkg
+		}
+	}
+
+	var result []api.Package
+	for _, e := range entries {
+		var pkgType string
+		switch e.Kind {
+		case "brew":
+			pkgType = "formula"
+		case "cask":
+			pkgType = "cask"
+		default:
+			continue
+		}
+
+		if pkg, ok := byKey[pkgType+":"+e.Name]; ok {
+			result = append(result, pkg)
+			continue
+		}
+		result = append(result, api.Package{Token: e.Name, Name: e.Name, Type: pkgType})
+	}
+	return result
+}
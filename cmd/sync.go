@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+var syncCommand = &command{
+	name:    "sync",
+	summary: "Install everything in the Brewfile with brew bundle",
+	flags: func(fs *flag.FlagSet) func(a *app, args []string) error {
+		cleanup := fs.Bool("cleanup", false, "Also uninstall packages that aren't in the Brewfile")
+
+		return func(a *app, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("sync takes no arguments")
+			}
+			return runSync(a, *cleanup)
+		}
+	},
+}
+
+// runSync runs brew bundle against the Brewfile
+func runSync(a *app, cleanup bool) error {
+	a.infof("🚀 Running brew bundle...")
+	if err := a.brewfile.RunBundle(); err != nil {
+		return fmt.Errorf("failed to run brew bundle: %w", err)
+	}
+
+	if cleanup {
+		a.infof("🧹 Removing packages not in the Brewfile...")
+		if err := a.runner.Run("bundle", "cleanup", "--file", a.brewfile.Path(), "--force"); err != nil {
+			return fmt.Errorf("failed to run brew bundle cleanup: %w", err)
+		}
+	}
+
+	fmt.Println("✨ Done!")
+	return nil
+}
@@ -4,8 +4,8 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/ktr0731/go-fuzzyfinder"
 	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/ui"
 )
 
 func main() {
@@ -45,10 +45,11 @@ func main() {
 	fmt.Println("───────────────────────────")
 	testFormat(packages, existing, "%s %s %-30s :: %-15s :: %s")
 	
-	// Test fuzzy finder display
-	fmt.Println("\n\n🔍 Testing Fuzzy Finder Display")
+	// Test the selector display
+	fmt.Println("\n\n🔍 Testing Package Selector")
 	fmt.Println("================================")
-	fmt.Println("(Press Ctrl+C to exit)\n")
+	fmt.Println("(Press Ctrl+C to exit)")
+	fmt.Println()
 	
 	testFuzzyFinder(packages, existing)
 }
@@ -78,45 +79,16 @@ func testFormat(packages []api.Package, existing map[string]bool, format string)
 }
 
 func testFuzzyFinder(packages []api.Package, existing map[string]bool) {
-	// Create display items
-	items := make([]string, len(packages))
-	for i, pkg := range packages {
-		statusIcon := "  "
-		if existing[pkg.Token] {
-			statusIcon = "✅"
-		}
-		
-		typeIcon := "⚡"
-		if pkg.Type == "cask" {
-			typeIcon = "🖥️"
-		}
-		
-		name := truncate(pkg.Token, 30)
-		version := truncate(pkg.Version, 15)
-		desc := pkg.Description
-		if len(desc) > 50 {
-			desc = desc[:47] + "..."
-		}
-		
-		// Test with double spaces
-		items[i] = fmt.Sprintf("%s %s %-30s  %-15s  %s", statusIcon, typeIcon, name, version, desc)
-	}
-	
-	// Show fuzzy finder
-	idx, err := fuzzyfinder.Find(
-		items,
-		func(i int) string {
-			return items[i]
-		},
-		fuzzyfinder.WithPromptString("Test search: "),
-	)
-	
+	// Show the package selector
+	selected, err := ui.ShowPackageSelector(packages, existing, ui.DefaultOptions())
 	if err != nil {
-		fmt.Printf("Error or cancelled: %v\n", err)
+		fmt.Printf("Error: %v\n", err)
 		return
 	}
 	
-	fmt.Printf("\nSelected: %s\n", items[idx])
+	for _, pkg := range selected {
+		fmt.Printf("\nSelected: %s\n", pkg.Token)
+	}
 }
 
 func truncate(s string, maxLen int) string {
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/ui"
+)
+
+// whatsNewOptions are the flags of the whatsnew command
+type whatsNewOptions struct {
+	since   string
+	pkgType string
+	list    bool
+	json    bool
+}
+
+// whatsNewReport is the JSON form of the whatsnew command
+type whatsNewReport struct {
+	Since      time.Time     `json:"since"`
+	Added      []api.Package `json:"added"`
+	Removed    []api.Package `json:"removed"`
+	Renamed    []renameJSON  `json:"renamed"`
+	Bumped     []bumpJSON    `json:"bumped"`
+	Deprecated []api.Package `json:"deprecated"`
+}
+
+type renameJSON struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Type string `json:"type"`
+}
+
+type bumpJSON struct {
+	Token string `json:"token"`
+	Type  string `json:"type"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// historyKeys maps package types to the cache keys of their indexes
+var historyKeys = []struct {
+	key, pkgType string
+}{
+	{"formulae", "formula"},
+	{"casks", "cask"},
+}
+
+var whatsNewCommand = &command{
+	name:    "whatsnew",
+	summary: "Show packages added, removed, renamed, bumped or deprecated since an earlier index",
+	flags: func(fs *flag.FlagSet) func(a *app, args []string) error {
+		var opts whatsNewOptions
+		fs.StringVar(&opts.since, "since", "", "Compare with the index from this long ago (e.g. 7d, 36h) or this date (YYYY-MM-DD)")
+		fs.StringVar(&opts.pkgType, "type", "", "Only show packages of this type (formula or cask)")
+		fs.BoolVar(&opts.list, "list", false, "List the kept indexes instead")
+		fs.BoolVar(&opts.json, "json", false, "Print changes as JSON")
+
+		return func(a *app, args []string) error {
+			if len(args) > 0 {
+				return usageErrorf("whatsnew takes no arguments")
+			}
+			return runWhatsNew(a, opts)
+		}
+	},
+}
+
+// runWhatsNew compares the current package index with an earlier one kept
+// in the cache
+func runWhatsNew(a *app, opts whatsNewOptions) error {
+	if err := checkType(opts.pkgType); err != nil {
+		return err
+	}
+
+	var cutoff time.Time
+	if opts.since != "" {
+		var err error
+		if cutoff, err = parseSince(opts.since); err != nil {
+			return usageErrorf("invalid -since %q: %v", opts.since, err)
+		}
+	}
+
+	if opts.list {
+		return listHistory(a)
+	}
+
+	// Loading the current index first moves a replaced one into the history
+	current, err := a.indexPackages()
+	if err != nil {
+		return fmt.Errorf("failed to fetch packages: %w", err)
+	}
+
+	var before, after []api.Package
+	var since time.Time
+	for _, h := range historyKeys {
+		if opts.pkgType != "" && opts.pkgType != h.pkgType {
+			continue
+		}
+
+		times, err := a.api.History(h.key)
+		if err != nil {
+			return fmt.Errorf("failed to read history: %w", err)
+		}
+		if len(times) == 0 {
+			a.debugf("No earlier %s index kept yet", h.key)
+			continue
+		}
+
+		t := pickHistory(times, cutoff)
+		packages, err := a.api.LoadHistory(h.key, t)
+		if err != nil {
+			return err
+		}
+		before = append(before, packages...)
+		after = append(after, filterType(current, h.pkgType)...)
+		if since.IsZero() || t.Before(since) {
+			since = t
+		}
+	}
+
+	if since.IsZero() {
+		fmt.Println("📭 No earlier package index yet. One is kept each time new data is downloaded, so check back after the next refresh.")
+		return nil
+	}
+	if !cutoff.IsZero() && since.After(cutoff) {
+		a.infof("⚠️  History only goes back to %s", since.Local().Format(time.DateTime))
+	}
+
+	changes := api.Compare(before, after)
+	if opts.json {
+		return printWhatsNewJSON(since, changes)
+	}
+	printChanges(since, changes)
+
+	if len(changes.Added) == 0 || a.opts.quiet || !isTerminal(os.Stdin) {
+		return nil
+	}
+	if !confirm(fmt.Sprintf("\nOpen the %d new packages in the selector?", len(changes.Added))) {
+		return nil
+	}
+
+	existing, err := a.loadExisting()
+	if err != nil {
+		existing = make(map[string]bool)
+	}
+	selected, err := ui.ShowPackageSelector(changes.Added, existing, a.uiOptions())
+	if err != nil {
+		return fmt.Errorf("error in package selector: %w", err)
+	}
+	if len(selected) == 0 {
+		fmt.Println("👋 No packages selected")
+		return nil
+	}
+	return addToBrewfile(a, selected, existing, true)
+}
+
+// parseSince turns a duration such as "7d" or "36h", or a date, into the
+// time to compare with
+func parseSince(value string) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Now().AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("want a duration like 7d or 36h, or a date like 2006-01-02")
+}
+
+// pickHistory returns the newest index downloaded at or before cutoff, or
+// the oldest one when all are newer. A zero cutoff picks the newest.
+func pickHistory(times []time.Time, cutoff time.Time) time.Time {
+	if cutoff.IsZero() {
+		return times[len(times)-1]
+	}
+
+	picked := times[0]
+	for _, t := range times {
+		if t.After(cutoff) {
+			break
+		}
+		picked = t
+	}
+	return picked
+}
+
+// listHistory prints the kept indexes
+func listHistory(a *app) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "INDEX\tDOWNLOADED\tAGE")
+	found := false
+	for _, h := range historyKeys {
+		times, err := a.api.History(h.key)
+		if err != nil {
+			return fmt.Errorf("failed to read history: %w", err)
+		}
+		for i := len(times) - 1; i >= 0; i-- {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", h.key, times[i].Local().Format(time.DateTime), ui.FormatAge(time.Since(times[i])))
+			found = true
+		}
+	}
+
+	if !found {
+		fmt.Println("📭 No earlier package index yet")
+		return nil
+	}
+	return w.Flush()
+}
+
+// printChanges prints each kind of change as its own section
+func printChanges(since time.Time, changes api.Changes) {
+	fmt.Printf("🆕 What's new since %s (%s ago)\n", since.Local().Format(time.DateTime), ui.FormatAge(time.Since(since)))
+	if changes.Empty() {
+		fmt.Println("\n✅ Nothing changed")
+		return
+	}
+
+	section := func(title string, n int, rows func(w *tabwriter.Writer)) {
+		if n == 0 {
+			return
+		}
+		fmt.Printf("\n%s (%d):\n", title, n)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		rows(w)
+		w.Flush()
+	}
+	packageRows := func(packages []api.Package) func(w *tabwriter.Writer) {
+		return func(w *tabwriter.Writer) {
+			for _, pkg := range packages {
+				fmt.Fprintf(w, "   %s\t%s\t%s\t%s\n", pkg.Type, pkg.Token, pkg.Version, pkg.Description)
+			}
+		}
+	}
+
+	section("✨ New", len(changes.Added), packageRows(changes.Added))
+	section("🔀 Renamed", len(changes.Renamed), func(w *tabwriter.Writer) {
+		for _, r := range changes.Renamed {
+			fmt.Fprintf(w, "   %s\t%s → %s\n", r.To.Type, r.From, r.To.Token)
+		}
+	})
+	section("⚠️  Newly deprecated", len(changes.Deprecated), packageRows(changes.Deprecated))
+	section("➖ Removed", len(changes.Removed), packageRows(changes.Removed))
+	section("⬆️  Version bumps", len(changes.Bumped), func(w *tabwriter.Writer) {
+		for _, b := range changes.Bumped {
+			fmt.Fprintf(w, "   %s\t%s\t%s → %s\n", b.To.Type, b.To.Token, b.From, b.To.PkgVersion())
+		}
+	})
+}
+
+func printWhatsNewJSON(since time.Time, changes api.Changes) error {
+	report := whatsNewReport{
+		Since:      since,
+		Added:      nonNil(changes.Added),
+		Removed:    nonNil(changes.Removed),
+		Renamed:    []renameJSON{},
+		Bumped:     []bumpJSON{},
+		Deprecated: nonNil(changes.Deprecated),
+	}
+	for _, r := range changes.Renamed {
+		report.Renamed = append(report.Renamed, renameJSON{From: r.From, To: r.To.Token, Type: r.To.Type})
+	}
+	for _, b := range changes.Bumped {
+		report.Bumped = append(report.Bumped, bumpJSON{Token: b.To.Token, Type: b.To.Type, From: b.From, To: b.To.PkgVersion()})
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// nonNil makes empty lists encode as [] rather than null
+func nonNil(packages []api.Package) []api.Package {
+	if packages == nil {
+		return []api.Package{}
+	}
+	return packages
+}
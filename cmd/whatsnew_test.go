@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	tests := []struct {
+		value   string
+		ago     time.Duration // expected distance from now
+		wantErr bool
+	}{
+		{value: "7d", ago: 7 * 24 * time.Hour},
+		{value: "0d", ago: 0},
+		{value: "36h", ago: 36 * time.Hour},
+		{value: "90m", ago: 90 * time.Minute},
+		{value: "-1d", wantErr: true},
+		{value: "-2h", wantErr: true},
+		{value: "week", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseSince(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSince(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		// Allow for a DST change within the window when counting days
+		if diff := time.Since(got) - tt.ago; diff < -time.Hour || diff > time.Hour {
+			t.Errorf("parseSince(%q) = %v, %v ago, want %v", tt.value, got, time.Since(got), tt.ago)
+		}
+	}
+
+	date, err := parseSince("2024-03-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local); !date.Equal(want) {
+		t.Errorf("parseSince(2024-03-01) = %v, want local midnight %v", date, want)
+	}
+}
+
+func TestPickHistory(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 3, d, 12, 0, 0, 0, time.UTC)
+	}
+	times := []time.Time{day(1), day(5), day(10)}
+
+	tests := []struct {
+		name   string
+		cutoff time.Time
+		want   time.Time
+	}{
+		{"no cutoff picks the newest", time.Time{}, day(10)},
+		{"between two", day(7), day(5)},
+		{"exactly at one", day(5), day(5)},
+		{"after all", day(20), day(10)},
+		{"before all picks the oldest", day(0), day(1)},
+	}
+	for _, tt := range tests {
+		if got := pickHistory(times, tt.cutoff); !got.Equal(tt.want) {
+			t.Errorf("%s: pickHistory = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,17 +1,18 @@
 module github.com/user/go-brew-search
 
-go 1.21
+go 1.22
 
-require github.com/ktr0731/go-fuzzyfinder v0.8.0
+require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/gdamore/tcell/v2 v2.7.1
+	github.com/klauspost/compress v1.18.0
+	github.com/mattn/go-runewidth v0.0.15
+	go.etcd.io/bbolt v1.3.11
+)
 
 require (
 	github.com/gdamore/encoding v1.0.0 // indirect
-	github.com/gdamore/tcell/v2 v2.7.1 // indirect
-	github.com/ktr0731/go-ansisgr v0.1.0 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
-	github.com/mattn/go-runewidth v0.0.15 // indirect
-	github.com/nsf/termbox-go v1.1.1 // indirect
-	github.com/pkg/errors v0.9.1 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
 	golang.org/x/sys v0.17.0 // indirect
 	golang.org/x/term v0.17.0 // indirect
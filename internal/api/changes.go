@@ -0,0 +1,105 @@
+package api
+
+import "sort"
+
+// Rename is a package that took on a new name
+type Rename struct {
+	From string
+	To   Package
+}
+
+// Bump is a package whose version or revision changed
+type Bump struct {
+	From string // the earlier PkgVersion
+	To   Package
+}
+
+// Changes are the differences between two versions of the package index
+type Changes struct {
+	Added      []Package
+	Removed    []Package
+	Renamed    []Rename
+	Bumped     []Bump
+	Deprecated []Package // deprecated since the older version
+}
+
+// Empty reports whether nothing changed
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Renamed) == 0 &&
+		len(c.Bumped) == 0 && len(c.Deprecated) == 0
+}
+
+// Compare lists what changed from old to new. A package that disappeared
+// while another of the same type lists it among its old names counts as
+// renamed rather than removed and added.
+func Compare(old, new []Package) Changes {
+	before := make(map[string]Package, len(old))
+	for _, pkg := range old {
+		before[pkg.Type+":"+pkg.Token] = pkg
+	}
+	after := make(map[string]Package, len(new))
+	for _, pkg := range new {
+		after[pkg.Type+":"+pkg.Token] = pkg
+	}
+
+	var changes Changes
+	renamed := make(map[string]bool)
+	for _, pkg := range new {
+		prev, ok := before[pkg.Type+":"+pkg.Token]
+		if !ok {
+			if from, ok := renamedFrom(pkg, before, after); ok {
+				changes.Renamed = append(changes.Renamed, Rename{From: from, To: pkg})
+				renamed[pkg.Type+":"+from] = true
+				continue
+			}
+			changes.Added = append(changes.Added, pkg)
+			continue
+		}
+
+		if prev.PkgVersion() != pkg.PkgVersion() {
+			changes.Bumped = append(changes.Bumped, Bump{From: prev.PkgVersion(), To: pkg})
+		}
+		if pkg.Deprecated && !prev.Deprecated {
+			changes.Deprecated = append(changes.Deprecated, pkg)
+		}
+	}
+
+	for _, pkg := range old {
+		key := pkg.Type + ":" + pkg.Token
+		if _, ok := after[key]; !ok && !renamed[key] {
+			changes.Removed = append(changes.Removed, pkg)
+		}
+	}
+
+	sortPackages(changes.Added)
+	sortPackages(changes.Removed)
+	sortPackages(changes.Deprecated)
+	sort.Slice(changes.Renamed, func(i, j int) bool {
+		return changes.Renamed[i].To.Token < changes.Renamed[j].To.Token
+	})
+	sort.Slice(changes.Bumped, func(i, j int) bool {
+		return changes.Bumped[i].To.Token < changes.Bumped[j].To.Token
+	})
+	return changes
+}
+
+// renamedFrom finds the old name of a new package among those that
+// disappeared
+func renamedFrom(pkg Package, before, after map[string]Package) (string, bool) {
+	for _, name := range pkg.OldNames {
+		key := pkg.Type + ":" + name
+		if _, existed := before[key]; !existed {
+			continue
+		}
+		if _, stays := after[key]; !stays {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func sortPackages(packages []Package) {
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Token < packages[j].Token
+	})
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+// describe lists changes as short lines, in the order Compare sorts them
+func describe(c Changes) []string {
+	var lines []string
+	for _, pkg := range c.Added {
+		lines = append(lines, "added "+pkg.Type+":"+pkg.Token)
+	}
+	for _, pkg := range c.Removed {
+		lines = append(lines, "removed "+pkg.Type+":"+pkg.Token)
+	}
+	for _, r := range c.Renamed {
+		lines = append(lines, "renamed "+r.From+" -> "+r.To.Token)
+	}
+	for _, b := range c.Bumped {
+		lines = append(lines, "bumped "+b.To.Token+" "+b.From+" -> "+b.To.PkgVersion())
+	}
+	for _, pkg := range c.Deprecated {
+		lines = append(lines, "deprecated "+pkg.Token)
+	}
+	return lines
+}
+
+func TestCompare(t *testing.T) {
+	jq := Package{Token: "jq", Type: "formula", Version: "1.7.1"}
+	exa := Package{Token: "exa", Type: "formula", Version: "0.10.1"}
+	eza := Package{Token: "eza", Type: "formula", Version: "0.18.0", OldNames: []string{"exa"}}
+	firefox := Package{Token: "firefox", Type: "cask", Version: "125.0"}
+
+	bumped := jq
+	bumped.Version = "1.8.0"
+	rebuilt := jq
+	rebuilt.Revision = 1
+	rebuiltAgain := jq
+	rebuiltAgain.Revision = 2
+	deprecated := exa
+	deprecated.Deprecated = true
+	caskEza := Package{Token: "eza", Type: "cask", OldNames: []string{"exa"}}
+
+	tests := []struct {
+		name     string
+		old, new []Package
+		want     []string
+	}{
+		{
+			name: "unchanged",
+			old:  []Package{jq, firefox},
+			new:  []Package{firefox, jq},
+		},
+		{
+			name: "added",
+			old:  []Package{jq},
+			new:  []Package{jq, firefox, exa},
+			want: []string{"added formula:exa", "added cask:firefox"},
+		},
+		{
+			name: "removed",
+			old:  []Package{jq, firefox},
+			new:  []Package{jq},
+			want: []string{"removed cask:firefox"},
+		},
+		{
+			name: "renamed through old names",
+			old:  []Package{exa, jq},
+			new:  []Package{eza, jq},
+			want: []string{"renamed exa -> eza"},
+		},
+		{
+			name: "old name still present",
+			old:  []Package{exa},
+			new:  []Package{exa, eza},
+			want: []string{"added formula:eza"},
+		},
+		{
+			name: "old name of another type",
+			old:  []Package{exa},
+			new:  []Package{caskEza},
+			want: []string{"added cask:eza", "removed formula:exa"},
+		},
+		{
+			name: "bumped",
+			old:  []Package{jq, firefox},
+			new:  []Package{bumped, firefox},
+			want: []string{"bumped jq 1.7.1 -> 1.8.0"},
+		},
+		{
+			name: "rebuilt",
+			old:  []Package{jq},
+			new:  []Package{rebuilt},
+			want: []string{"bumped jq 1.7.1 -> 1.7.1_1"},
+		},
+		{
+			name: "rebuilt again",
+			old:  []Package{rebuilt},
+			new:  []Package{rebuiltAgain},
+			want: []string{"bumped jq 1.7.1_1 -> 1.7.1_2"},
+		},
+		{
+			name: "deprecated",
+			old:  []Package{exa},
+			new:  []Package{deprecated},
+			want: []string{"deprecated exa"},
+		},
+		{
+			name: "still deprecated",
+			old:  []Package{deprecated},
+			new:  []Package{deprecated},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := Compare(tt.old, tt.new)
+			if got := describe(changes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compare = %q, want %q", got, tt.want)
+			}
+			if changes.Empty() != (len(tt.want) == 0) {
+				t.Errorf("Empty = %v with changes %q", changes.Empty(), tt.want)
+			}
+		})
+	}
+}
@@ -1,86 +1,223 @@
 package api
 
 import (
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/user/go-brew-search/internal/cache"
 )
 
-const (
-	formulaeAPIURL = "https://formulae.brew.sh/api/formula.json"
-	casksAPIURL    = "https://formulae.brew.sh/api/cask.json"
-)
+// ErrNoData is returned offline when neither formulae nor casks are cached
+var ErrNoData = errors.New("no package data available offline")
+
+// DefaultBaseURL serves the formula.json and cask.json indexes
+const DefaultBaseURL = "https://formulae.brew.sh/api"
 
 type Package struct {
-	Token       string `json:"token,omitempty"`       // for casks
-	Name        string `json:"name,omitempty"`         // for formulae
-	FullName    string `json:"full_name,omitempty"`    // for formulae
-	Description string `json:"desc,omitempty"`         // for both
-	Homepage    string `json:"homepage,omitempty"`     // for both
-	Version     string `json:"version,omitempty"`      // for both
-	Type        string // "formula" or "cask"
+	Token        string   `json:"token,omitempty"`        // for casks
+	Name         string   `json:"name,omitempty"`         // for formulae
+	FullName     string   `json:"full_name,omitempty"`    // for formulae
+	Description  string   `json:"desc,omitempty"`         // for both
+	Homepage     string   `json:"homepage,omitempty"`     // for both
+	Version      string   `json:"version,omitempty"`      // for both
+	Revision     int      `json:"revision,omitempty"`     // for formulae, rebuilds of the same version
+	Type         string   `json:"type"`                   // "formula" or "cask"
+	Tap          string   `json:"tap,omitempty"`          // for both
+	Aliases      []string `json:"aliases,omitempty"`      // for formulae
+	OldNames     []string `json:"oldnames,omitempty"`     // formula oldnames, cask old_tokens
+	Dependencies []string `json:"dependencies,omitempty"` // runtime formula and cask dependencies
+	Caveats      string   `json:"caveats,omitempty"`      // for both
+	License      string   `json:"license,omitempty"`      // for formulae
+	Deprecated   bool     `json:"deprecated,omitempty"`   // for both
 }
 
 type Client struct {
-	cache      *cache.Manager
+	cache      cache.Store
 	httpClient *http.Client
+	baseURL    string
+	policy     cache.Policy
+	offline    bool
+	refresh    bool
+
+	historySize int
+
+	snapshot     map[string][]Package // by cache key
+	snapshotTime map[string]time.Time
+
+	mu      sync.Mutex
+	updated map[string]time.Time // when the data in use was downloaded
+	missing []string             // keys with no data offline
+}
+
+// cacheSchema versions the cached []Package. Bump it whenever Package
+// changes so existing cache entries are fetched again.
+const cacheSchema = 2
+
+// DefaultTTL is how long cached package data stays fresh
+const DefaultTTL = 24 * time.Hour
+
+// DefaultPolicy keeps the bulk indexes for a day and package details, which
+// carry install analytics, for three days. Details are evicted once they
+// take more than 50 MB; the indexes are never evicted, so offline mode
+// always has something to use. Earlier versions of the indexes never
+// expire; SetHistorySize limits how many are kept.
+func DefaultPolicy() cache.Policy {
+	return cache.Policy{
+		TTL:       DefaultTTL,
+		TTLs:      map[string]time.Duration{DetailPrefix: 72 * time.Hour, HistoryPrefix: 0},
+		MaxSize:   50 << 20,
+		Evictable: []string{DetailPrefix},
+	}
 }
 
-func New(cacheManager *cache.Manager) *Client {
+// New returns a client that caches package data in store
+func New(store cache.Store) *Client {
+	if v, ok := store.(cache.Versioned); ok {
+		v.SetSchema(cacheSchema)
+	}
+
 	return &Client{
-		cache: cacheManager,
+		cache: store,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		baseURL:      DefaultBaseURL,
+		policy:       DefaultPolicy(),
+		historySize:  DefaultHistorySize,
+		snapshot:     make(map[string][]Package),
+		snapshotTime: make(map[string]time.Time),
+		updated:      make(map[string]time.Time),
+	}
+}
+
+// SetBaseURL points the client at a mirror of the Homebrew API
+func (c *Client) SetBaseURL(baseURL string) {
+	c.baseURL = strings.TrimRight(baseURL, "/")
+}
+
+// SetTTL sets how long cached package data is used before it is downloaded
+// again, for keys without a more specific TTL in the policy
+func (c *Client) SetTTL(ttl time.Duration) {
+	c.policy.TTL = ttl
+}
+
+// SetPolicy replaces the cache policy
+func (c *Client) SetPolicy(policy cache.Policy) {
+	c.policy = policy
+}
+
+// Policy returns the cache policy in use
+func (c *Client) Policy() cache.Policy {
+	return c.policy
+}
+
+// SetOffline stops the client from using the network. Cached data is used
+// even when it has expired.
+func (c *Client) SetOffline(offline bool) {
+	c.offline = offline
+}
+
+// Offline reports whether the client avoids the network
+func (c *Client) Offline() bool {
+	return c.offline
+}
+
+// Updated returns when the oldest of the loaded package data was
+// downloaded, or the zero time before anything was loaded
+func (c *Client) Updated() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var oldest time.Time
+	for _, t := range c.updated {
+		if oldest.IsZero() || t.Before(oldest) {
+			oldest = t
+		}
+	}
+	return oldest
+}
+
+// Missing lists the package types ("formulae", "casks") that had no data
+// in the last offline load
+func (c *Client) Missing() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.missing...)
+}
+
+func (c *Client) setUpdated(key string, t time.Time) {
+	c.mu.Lock()
+	c.updated[key] = t
+	c.mu.Unlock()
+}
+
+// Refresh downloads the package data again, ignoring the cache TTL, and
+// stores it in the cache
+func (c *Client) Refresh() ([]Package, error) {
+	if c.offline {
+		return nil, fmt.Errorf("cannot refresh the cache while offline")
 	}
+
+	c.refresh = true
+	defer func() { c.refresh = false }()
+
+	return c.FetchAllPackages()
 }
 
 func (c *Client) FetchAllPackages() ([]Package, error) {
+	return c.StreamPackages(nil)
+}
+
+// StreamPackages fetches formulae and casks concurrently like
+// FetchAllPackages, and also passes each to fn, if not nil, as soon as it is
+// loaded. Calls to fn don't overlap.
+func (c *Client) StreamPackages(fn func([]Package)) ([]Package, error) {
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 	var allPackages []Package
 	var fetchErr error
+	var missing []string
 
-	wg.Add(2)
-
-	// Fetch formulae
-	go func() {
+	fetch := func(name string, load func() ([]Package, error)) {
 		defer wg.Done()
-		formulae, err := c.fetchFormulae()
-		if err != nil {
-			mu.Lock()
-			fetchErr = fmt.Errorf("failed to fetch formulae: %w", err)
-			mu.Unlock()
-			return
-		}
+		packages, err := load()
 		mu.Lock()
-		allPackages = append(allPackages, formulae...)
-		mu.Unlock()
-	}()
-
-	// Fetch casks
-	go func() {
-		defer wg.Done()
-		casks, err := c.fetchCasks()
+		defer mu.Unlock()
 		if err != nil {
-			mu.Lock()
-			fetchErr = fmt.Errorf("failed to fetch casks: %w", err)
-			mu.Unlock()
+			fetchErr = fmt.Errorf("failed to fetch %s: %w", name, err)
+			missing = append(missing, name)
 			return
 		}
-		mu.Lock()
-		allPackages = append(allPackages, casks...)
-		mu.Unlock()
-	}()
+		allPackages = append(allPackages, packages...)
+		if fn != nil {
+			fn(packages)
+		}
+	}
 
+	wg.Add(2)
+	go fetch("formulae", c.fetchFormulae)
+	go fetch("casks", c.fetchCasks)
 	wg.Wait()
 
-	if fetchErr != nil {
+	c.mu.Lock()
+	c.missing = nil
+	if c.offline {
+		c.missing = missing
+	}
+	c.mu.Unlock()
+
+	// Offline, whatever data there is beats none
+	if c.offline && len(missing) == 2 {
+		return nil, fmt.Errorf("%w: %v", ErrNoData, fetchErr)
+	}
+	if fetchErr != nil && !c.offline {
 		return nil, fetchErr
 	}
 
@@ -88,61 +225,98 @@ func (c *Client) FetchAllPackages() ([]Package, error) {
 }
 
 func (c *Client) fetchFormulae() ([]Package, error) {
+	return c.load("formulae", c.baseURL+"/formula.json", c.parseFormulae)
+}
+
+func (c *Client) fetchCasks() ([]Package, error) {
+	return c.load("casks", c.baseURL+"/cask.json", c.parseCasks)
+}
+
+// load returns the packages for key, from the cache when it is fresh and
+// from url otherwise. The parsed packages are cached rather than the raw
+// API data, so a warm start only decodes the binary cache entry.
+func (c *Client) load(key, url string, parse func([]map[string]any) []Package) ([]Package, error) {
+	var packages []Package
+
+	if c.offline {
+		if snapshot, ok := c.snapshot[key]; ok {
+			c.setUpdated(key, c.snapshotTime[key])
+			return snapshot, nil
+		}
+
+		info, err := c.cache.Get(key, &packages)
+		if err != nil {
+			return nil, fmt.Errorf("offline and no cached %s: %w", key, err)
+		}
+		c.setUpdated(key, info.Stored)
+		return packages, nil
+	}
+
 	// Check cache first
-	var formulae []map[string]any
-	if err := c.cache.Get("formulae", &formulae); err == nil {
-		return c.parseFormulae(formulae), nil
+	if !c.refresh {
+		if info, err := c.cache.Stat(key); err == nil && c.policy.Fresh(key, info.Stored) {
+			if info, err := c.cache.Get(key, &packages); err == nil {
+				c.setUpdated(key, info.Stored)
+				return packages, nil
+			}
+			packages = nil
+		}
 	}
 
 	// Fetch from API
-	resp, err := c.httpClient.Get(formulaeAPIURL)
+	body, err := c.download(url)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	source := sha256.Sum256(body)
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+	// An unchanged download doesn't need parsing again; a changed one
+	// moves the previous index into the history
+	if !c.cachedSource(key, source, &packages) {
+		c.archive(key)
 
-	if err := json.Unmarshal(body, &formulae); err != nil {
-		return nil, err
+		var data []map[string]any
+		if err := json.Unmarshal(body, &data); err != nil {
+			return nil, err
+		}
+		packages = parse(data)
 	}
 
 	// Cache the result
-	c.cache.Set("formulae", formulae)
+	if err := c.cache.Set(key, packages, source); err != nil && c.refresh {
+		return nil, fmt.Errorf("failed to cache %s: %w", key, err)
+	}
+	c.setUpdated(key, time.Now())
 
-	return c.parseFormulae(formulae), nil
+	return packages, nil
 }
 
-func (c *Client) fetchCasks() ([]Package, error) {
-	// Check cache first
-	var casks []map[string]any
-	if err := c.cache.Get("casks", &casks); err == nil {
-		return c.parseCasks(casks), nil
-	}
-
-	// Fetch from API
-	resp, err := c.httpClient.Get(casksAPIURL)
+// download returns the body of url
+func (c *Client) download(url string) ([]byte, error) {
+	resp, err := c.httpClient.Get(url)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status from %s: %s", url, resp.Status)
 	}
 
-	if err := json.Unmarshal(body, &casks); err != nil {
-		return nil, err
-	}
+	return io.ReadAll(resp.Body)
+}
 
-	// Cache the result
-	c.cache.Set("casks", casks)
+// cachedSource loads the cached packages for key if they were built from
+// the same download
+func (c *Client) cachedSource(key string, source [32]byte, packages *[]Package) bool {
+	info, err := c.cache.Stat(key)
+	if err != nil || info.Source != source {
+		return false
+	}
 
-	return c.parseCasks(casks), nil
+	*packages = nil
+	_, err = c.cache.Get(key, packages)
+	return err == nil
 }
 
 func (c *Client) parseFormulae(formulae []map[string]any) []Package {
@@ -175,6 +349,30 @@ func (c *Client) parseFormulae(formulae []map[string]any) []Package {
 			}
 		}
 
+		if revision, ok := f["revision"].(float64); ok {
+			pkg.Revision = int(revision)
+		}
+
+		if tap, ok := f["tap"].(string); ok {
+			pkg.Tap = tap
+		}
+
+		pkg.Aliases = stringList(f["aliases"])
+		pkg.OldNames = stringList(f["oldnames"])
+		pkg.Dependencies = stringList(f["dependencies"])
+
+		if caveats, ok := f["caveats"].(string); ok {
+			pkg.Caveats = caveats
+		}
+
+		if license, ok := f["license"].(string); ok {
+			pkg.License = license
+		}
+
+		if deprecated, ok := f["deprecated"].(bool); ok {
+			pkg.Deprecated = deprecated
+		}
+
 		if pkg.Name != "" {
 			packages = append(packages, pkg)
 		}
@@ -212,9 +410,43 @@ func (c *Client) parseCasks(casks []map[string]any) []Package {
 			pkg.Version = version
 		}
 
+		if tap, ok := cs["tap"].(string); ok {
+			pkg.Tap = tap
+		}
+
+		pkg.OldNames = stringList(cs["old_tokens"])
+
+		if dependsOn, ok := cs["depends_on"].(map[string]any); ok {
+			pkg.Dependencies = append(stringList(dependsOn["formula"]), stringList(dependsOn["cask"])...)
+		}
+
+		if caveats, ok := cs["caveats"].(string); ok {
+			pkg.Caveats = caveats
+		}
+
+		if deprecated, ok := cs["deprecated"].(bool); ok {
+			pkg.Deprecated = deprecated
+		}
+
 		if pkg.Token != "" {
 			packages = append(packages, pkg)
 		}
 	}
 	return packages
-}
\ No newline at end of file
+}
+
+// stringList converts a JSON array of strings, skipping anything else
+func stringList(v any) []string {
+	items, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+
+	var result []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"testing"
+
+	"github.com/user/go-brew-search/internal/cache"
+)
+
+// newTestAPI serves a small formula and cask index, counting the requests
+// it gets
+func newTestAPI(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/formula.json", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`[{"name": "jq", "full_name": "jq", "desc": "JSON processor", "versions": {"stable": "1.7.1"}, "tap": "homebrew/core"}]`))
+	})
+	mux.HandleFunc("/cask.json", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`[{"token": "firefox", "name": ["Mozilla Firefox"], "version": "125.0", "tap": "homebrew/cask"}]`))
+	})
+	mux.HandleFunc("/formula/jq.json", func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(`{"analytics": {"install": {"30d": {"jq": 10, "jq --HEAD": 2, "jqp": 99}}}}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func tokens(packages []Package) []string {
+	var result []string
+	for _, pkg := range packages {
+		result = append(result, pkg.Token)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestFetchAllPackagesCaches(t *testing.T) {
+	server, requests := newTestAPI(t)
+	store := cache.NewMemory()
+	client := New(store)
+	client.SetBaseURL(server.URL)
+
+	packages, err := client.FetchAllPackages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tokens(packages); len(got) != 2 || got[0] != "firefox" || got[1] != "jq" {
+		t.Fatalf("packages = %q, want firefox and jq", got)
+	}
+	for _, key := range []string{"formulae", "casks"} {
+		if _, err := store.Stat(key); err != nil {
+			t.Errorf("%s not cached: %v", key, err)
+		}
+	}
+
+	// Fresh cache entries are used without downloading again
+	if _, err := client.FetchAllPackages(); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("%d requests, want 2", n)
+	}
+
+	// Offline, the cache is used whatever its age
+	server.Close()
+	offline := New(store)
+	offline.SetBaseURL(server.URL)
+	offline.SetTTL(0)
+	offline.SetOffline(true)
+	if packages, err := offline.FetchAllPackages(); err != nil || len(packages) != 2 {
+		t.Errorf("offline FetchAllPackages = %d packages, %v, want the cached 2", len(packages), err)
+	}
+}
+
+func TestFetchAllPackagesOfflineWithoutCache(t *testing.T) {
+	client := New(cache.NewMemory())
+	client.SetOffline(true)
+
+	if _, err := client.FetchAllPackages(); err == nil {
+		t.Fatal("FetchAllPackages succeeded offline with an empty cache")
+	}
+	if missing := client.Missing(); len(missing) != 2 {
+		t.Errorf("Missing = %q, want formulae and casks", missing)
+	}
+}
+
+func TestLoadSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "formula.json")
+	if err := os.WriteFile(path, []byte(`[{"name": "jq", "full_name": "jq", "versions": {"stable": "1.7.1"}}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := New(cache.NewMemory())
+	if err := client.LoadSnapshot(path); err != nil {
+		t.Fatal(err)
+	}
+	if !client.Offline() {
+		t.Error("LoadSnapshot did not turn on offline mode")
+	}
+
+	// Casks have neither a snapshot nor a cache entry
+	packages, err := client.FetchAllPackages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tokens(packages); len(got) != 1 || got[0] != "jq" {
+		t.Errorf("packages = %q, want jq", got)
+	}
+	if missing := client.Missing(); len(missing) != 1 || missing[0] != "casks" {
+		t.Errorf("Missing = %q, want casks", missing)
+	}
+
+	empty := filepath.Join(dir, "empty.json")
+	os.WriteFile(empty, []byte(`[]`), 0644)
+	if err := client.LoadSnapshot(empty); err == nil {
+		t.Error("LoadSnapshot accepted a file with no packages")
+	}
+}
+
+func TestDetail(t *testing.T) {
+	server, requests := newTestAPI(t)
+	client := New(cache.NewMemory())
+	client.SetBaseURL(server.URL)
+	jq := Package{Token: "jq", Type: "formula", Tap: "homebrew/core"}
+
+	if _, err := client.CachedDetail(jq); err == nil {
+		t.Error("CachedDetail succeeded before anything was cached")
+	}
+
+	detail, err := client.FetchDetail(jq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.Analytics.Installs30d != 12 {
+		t.Errorf("Installs30d = %d, want 12, summed over jq's install options only", detail.Analytics.Installs30d)
+	}
+
+	cached, err := client.CachedDetail(jq)
+	if err != nil || *cached != *detail {
+		t.Errorf("CachedDetail = %+v, %v, want %+v", cached, err, detail)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("%d requests, want 1", n)
+	}
+
+	if _, err := client.FetchDetail(Package{Token: "foo", Type: "formula", Tap: "user/tools"}); err == nil {
+		t.Error("FetchDetail succeeded for a third-party tap")
+	}
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DetailPrefix groups the cached per-package details
+const DetailPrefix = "detail/"
+
+// Analytics counts installs over the last 30, 90 and 365 days
+type Analytics struct {
+	Installs30d  int `json:"installs_30d"`
+	Installs90d  int `json:"installs_90d"`
+	Installs365d int `json:"installs_365d"`
+}
+
+// Detail is what the per-package API adds to the bulk index
+type Detail struct {
+	Analytics Analytics `json:"analytics"`
+}
+
+// CachedDetail returns the cached per-package details of pkg whatever their
+// age, without touching the network
+func (c *Client) CachedDetail(pkg Package) (*Detail, error) {
+	var detail Detail
+	if _, err := c.cache.Get(detailKey(pkg), &detail); err != nil {
+		return nil, fmt.Errorf("no cached details for %s: %w", pkg.Token, err)
+	}
+	return &detail, nil
+}
+
+// FetchDetail returns the per-package details of pkg, from the cache when
+// they are fresh. Offline, cached details are used whatever their age.
+// Packages from third-party taps are not in the API.
+func (c *Client) FetchDetail(pkg Package) (*Detail, error) {
+	if pkg.Tap != "" && pkg.Tap != "homebrew/core" && pkg.Tap != "homebrew/cask" {
+		return nil, fmt.Errorf("%s is from %s, which the API does not cover", pkg.Token, pkg.Tap)
+	}
+
+	key := detailKey(pkg)
+	var detail Detail
+	if info, err := c.cache.Stat(key); err == nil && (c.offline || c.policy.Fresh(key, info.Stored)) {
+		if _, err := c.cache.Get(key, &detail); err == nil {
+			return &detail, nil
+		}
+	}
+	if c.offline {
+		return nil, fmt.Errorf("offline and no cached details for %s", pkg.Token)
+	}
+
+	body, err := c.download(c.baseURL + "/" + detailKind(pkg) + "/" + url.PathEscape(pkg.Token) + ".json")
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		Analytics struct {
+			Install map[string]map[string]int `json:"install"`
+		} `json:"analytics"`
+	}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse details of %s: %w", pkg.Token, err)
+	}
+
+	detail.Analytics = Analytics{
+		Installs30d:  sumInstalls(raw.Analytics.Install["30d"], pkg.Token),
+		Installs90d:  sumInstalls(raw.Analytics.Install["90d"], pkg.Token),
+		Installs365d: sumInstalls(raw.Analytics.Install["365d"], pkg.Token),
+	}
+
+	c.cache.Set(key, detail, sha256.Sum256(body))
+	return &detail, nil
+}
+
+// detailKind is the API path segment of pkg's details
+func detailKind(pkg Package) string {
+	if pkg.Type == "cask" {
+		return "cask"
+	}
+	return "formula"
+}
+
+// detailKey is the cache key of pkg's details
+func detailKey(pkg Package) string {
+	return DetailPrefix + detailKind(pkg) + "/" + pkg.Token
+}
+
+// sumInstalls adds up the installs of a package across its install options,
+// which the API counts separately (e.g. "jq" and "jq --HEAD")
+func sumInstalls(counts map[string]int, token string) int {
+	total := 0
+	for name, n := range counts {
+		if name == token || strings.HasPrefix(name, token+" ") {
+			total += n
+		}
+	}
+	return total
+}
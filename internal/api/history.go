@@ -0,0 +1,95 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HistoryPrefix groups the earlier versions of the package index
+const HistoryPrefix = "history/"
+
+// DefaultHistorySize is how many earlier versions of each index are kept
+const DefaultHistorySize = 7
+
+// historyTimeFormat names history entries after their download time, so
+// they sort by age
+const historyTimeFormat = "20060102T150405Z"
+
+// SetHistorySize sets how many earlier versions of each index are kept
+// when new data is downloaded; 0 keeps none
+func (c *Client) SetHistorySize(n int) {
+	c.historySize = n
+}
+
+// History lists when the kept versions of the index for key ("formulae" or
+// "casks") were downloaded, oldest first. The data in use is not included.
+func (c *Client) History(key string) ([]time.Time, error) {
+	infos, err := c.cache.List()
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := HistoryPrefix + key + "/"
+	var times []time.Time
+	for _, info := range infos {
+		name, ok := strings.CutPrefix(info.Key, prefix)
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(historyTimeFormat, name)
+		if err != nil {
+			continue
+		}
+		times = append(times, t)
+	}
+
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+	return times, nil
+}
+
+// LoadHistory returns the version of the index for key downloaded at t
+func (c *Client) LoadHistory(key string, t time.Time) ([]Package, error) {
+	var packages []Package
+	if _, err := c.cache.Get(historyKey(key, t), &packages); err != nil {
+		return nil, fmt.Errorf("failed to load %s from %s: %w", key, t.Local().Format(time.DateTime), err)
+	}
+	return packages, nil
+}
+
+func historyKey(key string, t time.Time) string {
+	return HistoryPrefix + key + "/" + t.UTC().Format(historyTimeFormat)
+}
+
+// archive keeps the cached index for key before it is replaced by a new
+// download, and drops the oldest versions beyond the history size. Errors
+// only cost history, so they are ignored.
+func (c *Client) archive(key string) {
+	if c.historySize <= 0 {
+		return
+	}
+
+	info, err := c.cache.Stat(key)
+	if err != nil {
+		return
+	}
+	var packages []Package
+	if _, err := c.cache.Get(key, &packages); err != nil {
+		return
+	}
+	if err := c.cache.Set(historyKey(key, info.Stored), packages, info.Source); err != nil {
+		return
+	}
+
+	times, err := c.History(key)
+	if err != nil {
+		return
+	}
+	for len(times) > c.historySize {
+		c.cache.Delete(historyKey(key, times[0]))
+		times = times[1:]
+	}
+}
@@ -0,0 +1,20 @@
+package api
+
+import "fmt"
+
+// InstallCommand returns the brew command that installs the package
+func (p Package) InstallCommand() string {
+	if p.Type == "cask" {
+		return "brew install --cask " + p.Token
+	}
+	return "brew install " + p.Token
+}
+
+// PkgVersion returns the version as brew list --versions shows it, with the
+// revision appended as "_1" when there is one
+func (p Package) PkgVersion() string {
+	if p.Revision > 0 && p.Version != "" {
+		return fmt.Sprintf("%s_%d", p.Version, p.Revision)
+	}
+	return p.Version
+}
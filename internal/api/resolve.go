@@ -0,0 +1,285 @@
+package api
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// How a name matched a package. Tokens, full names and tap-qualified names
+// are canonical: brew bundle accepts them as they are.
+const (
+	MatchToken    = "token"
+	MatchFullName = "full name"
+	MatchTapped   = "tap-qualified name"
+	MatchAlias    = "alias"
+	MatchOldName  = "old name"
+)
+
+// maxSuggestions is how many near misses a failed resolution offers
+const maxSuggestions = 3
+
+// Resolution is what a name refers to
+type Resolution struct {
+	Name        string    // as given
+	Packages    []Package // a formula and a cask can share a name, so there may be two
+	Match       string    // how Packages matched the name; empty if nothing did
+	Suggestions []Package // near misses when nothing matched, closest first
+}
+
+// Canonical reports whether the name is one of the packages' own names
+// rather than an alias or old name
+func (r Resolution) Canonical() bool {
+	switch r.Match {
+	case MatchToken, MatchFullName, MatchTapped:
+		return true
+	}
+	return false
+}
+
+// Resolver maps names to packages by token, full name, tap-qualified name,
+// alias and old name, case-insensitively, and suggests packages for names
+// that are slightly off
+type Resolver struct {
+	packages []Package
+	names    map[string][]nameRef // by lower-case name
+	short    []shortName          // for suggestions
+}
+
+type nameRef struct {
+	pkg   int
+	match string
+}
+
+// shortName is a token, alias or old name suggestions are drawn from
+type shortName struct {
+	name  string
+	runes int
+}
+
+// NewResolver indexes the names of packages
+func NewResolver(packages []Package) *Resolver {
+	r := &Resolver{
+		packages: packages,
+		names:    make(map[string][]nameRef),
+	}
+
+	short := make(map[string]bool)
+	add := func(name string, pkg int, match string) {
+		name = strings.ToLower(name)
+		if name == "" {
+			return
+		}
+		for _, ref := range r.names[name] {
+			if ref.pkg == pkg {
+				return
+			}
+		}
+		if match != MatchFullName && match != MatchTapped && !short[name] {
+			short[name] = true
+			r.short = append(r.short, shortName{name: name, runes: utf8.RuneCountInString(name)})
+		}
+		r.names[name] = append(r.names[name], nameRef{pkg: pkg, match: match})
+	}
+
+	for i, pkg := range packages {
+		add(pkg.Token, i, MatchToken)
+		if pkg.Type == "formula" {
+			add(pkg.FullName, i, MatchFullName)
+		}
+		if pkg.Tap != "" {
+			add(pkg.Tap+"/"+pkg.Token, i, MatchTapped)
+		}
+		for _, alias := range pkg.Aliases {
+			add(alias, i, MatchAlias)
+		}
+		for _, old := range pkg.OldNames {
+			add(old, i, MatchOldName)
+		}
+	}
+	return r
+}
+
+// Resolve finds the packages of pkgType, or of either type if it is empty,
+// that name refers to. Canonical names win over aliases, and aliases over old
+// names, so "python" finds the formula aliased to it rather than a package
+// that merely used to be called that. If nothing matches, the Resolution
+// suggests packages whose names are a few typos away.
+func (r *Resolver) Resolve(name, pkgType string) Resolution {
+	res := Resolution{Name: name}
+	key := normalizeTap(strings.ToLower(strings.TrimSpace(name)))
+	if key == "" {
+		return res
+	}
+
+	best := -1
+	for _, ref := range r.names[key] {
+		pkg := r.packages[ref.pkg]
+		if pkgType != "" && pkg.Type != pkgType {
+			continue
+		}
+
+		rank := matchRank(ref.match)
+		switch {
+		case best == -1 || rank < best:
+			best = rank
+			res.Packages = []Package{pkg}
+			res.Match = ref.match
+		case rank == best:
+			res.Packages = append(res.Packages, pkg)
+		}
+	}
+
+	if len(res.Packages) == 0 {
+		res.Suggestions = r.suggest(key, pkgType)
+	}
+	return res
+}
+
+// matchRank orders matches, canonical names first
+func matchRank(match string) int {
+	switch match {
+	case MatchAlias:
+		return 1
+	case MatchOldName:
+		return 2
+	}
+	return 0
+}
+
+// normalizeTap drops the "homebrew-" prefix of a tap repository, as in
+// "user/homebrew-tools/package", which brew accepts for "user/tools/package"
+func normalizeTap(name string) string {
+	parts := strings.Split(name, "/")
+	if len(parts) != 3 {
+		return name
+	}
+	parts[1] = strings.TrimPrefix(parts[1], "homebrew-")
+	return strings.Join(parts, "/")
+}
+
+// suggest returns the packages whose tokens, aliases or old names are
+// closest to name, within a distance that grows with its length
+func (r *Resolver) suggest(name, pkgType string) []Package {
+	// Tap-qualified names are compared by the package part
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	runes := utf8.RuneCountInString(name)
+	limit := maxDistance(runes)
+	if limit == 0 {
+		return nil
+	}
+
+	type candidate struct {
+		pkg      int
+		distance int
+		alias    bool
+	}
+	var candidates []candidate
+	seen := make(map[int]int) // package → index in candidates
+	for _, s := range r.short {
+		if diff := s.runes - runes; diff > limit || -diff > limit {
+			continue
+		}
+		d := editDistance(name, s.name)
+		if d > limit {
+			continue
+		}
+
+		for _, ref := range r.names[s.name] {
+			if pkgType != "" && r.packages[ref.pkg].Type != pkgType {
+				continue
+			}
+			c := candidate{pkg: ref.pkg, distance: d, alias: ref.match != MatchToken}
+			if i, ok := seen[ref.pkg]; ok {
+				if prev := candidates[i]; d < prev.distance || d == prev.distance && prev.alias && !c.alias {
+					candidates[i] = c
+				}
+				continue
+			}
+			seen[ref.pkg] = len(candidates)
+			candidates = append(candidates, c)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		if a.alias != b.alias {
+			return !a.alias
+		}
+		return r.packages[a.pkg].Token < r.packages[b.pkg].Token
+	})
+
+	var suggestions []Package
+	for _, c := range candidates {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		suggestions = append(suggestions, r.packages[c.pkg])
+	}
+	return suggestions
+}
+
+// maxDistance is how many typos a name of n characters may have and still
+// get suggestions. Very short names would match too much to be useful.
+func maxDistance(n int) int {
+	switch {
+	case n < 3:
+		return 0
+	case n < 5:
+		return 1
+	case n < 9:
+		return 2
+	}
+	return 3
+}
+
+// editDistance returns the Damerau-Levenshtein distance between a and b: the
+// fewest insertions, deletions, substitutions and swaps of adjacent
+// characters that turn one into the other, counting characters that were
+// swapped and then edited around
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	inf := len(s) + len(t)
+
+	// d is offset by one row and column, which hold inf, so transpositions
+	// never reach outside the table
+	d := make([][]int, len(s)+2)
+	for i := range d {
+		d[i] = make([]int, len(t)+2)
+	}
+	d[0][0] = inf
+	for i := 0; i <= len(s); i++ {
+		d[i+1][0] = inf
+		d[i+1][1] = i
+	}
+	for j := 0; j <= len(t); j++ {
+		d[0][j+1] = inf
+		d[1][j+1] = j
+	}
+
+	lastRow := make(map[rune]int) // last row each character of s was seen in
+	for i := 1; i <= len(s); i++ {
+		lastCol := 0 // last column in this row where the characters matched
+		for j := 1; j <= len(t); j++ {
+			i1, j1 := lastRow[t[j-1]], lastCol
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+				lastCol = j
+			}
+			d[i+1][j+1] = min(
+				d[i][j]+cost, // substitution
+				d[i+1][j]+1,  // insertion
+				d[i][j+1]+1,  // deletion
+				d[i1][j1]+(i-i1-1)+1+(j-j1-1), // transposition
+			)
+		}
+		lastRow[s[i-1]] = i
+	}
+	return d[len(s)+1][len(t)+1]
+}
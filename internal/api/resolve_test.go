@@ -0,0 +1,121 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+var resolvePackages = []Package{
+	{Token: "python@3.13", Name: "python@3.13", FullName: "python@3.13", Type: "formula", Tap: "homebrew/core", Aliases: []string{"python", "python3"}},
+	{Token: "python@3.12", Name: "python@3.12", FullName: "python@3.12", Type: "formula", Tap: "homebrew/core", OldNames: []string{"python"}},
+	{Token: "ripgrep", Name: "ripgrep", FullName: "ripgrep", Type: "formula", Tap: "homebrew/core", Aliases: []string{"rg"}},
+	{Token: "docker", Name: "docker", FullName: "docker", Type: "formula", Tap: "homebrew/core"},
+	{Token: "docker", Name: "docker", FullName: "Docker Desktop", Type: "cask", Tap: "homebrew/cask"},
+	{Token: "fd", Name: "fd", FullName: "user/tools/fd", Type: "formula", Tap: "user/tools"},
+	{Token: "visual-studio-code", Name: "visual-studio-code", Type: "cask", Tap: "homebrew/cask", OldNames: []string{"vscode"}},
+}
+
+func TestResolve(t *testing.T) {
+	r := NewResolver(resolvePackages)
+
+	tests := []struct {
+		name, pkgType string
+		want          []string // "type:token"
+		match         string
+	}{
+		{"ripgrep", "", []string{"formula:ripgrep"}, MatchToken},
+		{"  RipGrep ", "", []string{"formula:ripgrep"}, MatchToken},
+		{"rg", "", []string{"formula:ripgrep"}, MatchAlias},
+		{"python", "", []string{"formula:python@3.13"}, MatchAlias},
+		{"homebrew/core/ripgrep", "", []string{"formula:ripgrep"}, MatchTapped},
+		{"user/homebrew-tools/fd", "", []string{"formula:fd"}, MatchFullName},
+		{"user/tools/fd", "", []string{"formula:fd"}, MatchFullName},
+		{"docker", "", []string{"formula:docker", "cask:docker"}, MatchToken},
+		{"docker", "cask", []string{"cask:docker"}, MatchToken},
+		{"vscode", "", []string{"cask:visual-studio-code"}, MatchOldName},
+		{"Docker Desktop", "", nil, ""}, // cask names aren't something brew accepts
+		{"rg", "cask", nil, ""},
+		{"", "", nil, ""},
+	}
+	for _, tt := range tests {
+		res := r.Resolve(tt.name, tt.pkgType)
+		var got []string
+		for _, pkg := range res.Packages {
+			got = append(got, pkg.Type+":"+pkg.Token)
+		}
+		if !reflect.DeepEqual(got, tt.want) || res.Match != tt.match {
+			t.Errorf("Resolve(%q, %q) = %q by %q, want %q by %q", tt.name, tt.pkgType, got, res.Match, tt.want, tt.match)
+		}
+		if res.Canonical() != (tt.match == MatchToken || tt.match == MatchFullName || tt.match == MatchTapped) {
+			t.Errorf("Resolve(%q).Canonical() = %v", tt.name, res.Canonical())
+		}
+	}
+}
+
+func TestResolveSuggestions(t *testing.T) {
+	r := NewResolver(resolvePackages)
+
+	tests := []struct {
+		name, pkgType string
+		want          []string
+	}{
+		{"ripgerp", "", []string{"ripgrep"}},
+		{"dokcer", "", []string{"docker", "docker"}},
+		{"dokcer", "cask", []string{"docker"}},
+		{"homebrew/core/ripgre", "", []string{"ripgrep"}},
+		{"pyton", "", []string{"python@3.12", "python@3.13"}},
+		{"vscod", "", []string{"visual-studio-code"}},
+		{"rq", "", nil}, // too short to guess
+		{"kubernetes", "", nil},
+	}
+	for _, tt := range tests {
+		res := r.Resolve(tt.name, tt.pkgType)
+		if len(res.Packages) != 0 {
+			t.Errorf("Resolve(%q) matched %v", tt.name, res.Packages)
+			continue
+		}
+		var got []string
+		for _, pkg := range res.Suggestions {
+			got = append(got, pkg.Token)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("suggestions for %q = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"ripgrep", "ripgrep", 0},
+		{"ripgrep", "ripgre", 1},
+		{"ripgrep", "rpigrep", 1},
+		{"docker", "dokcer", 1},
+		{"kitten", "sitting", 3},
+		{"ca", "abc", 2}, // swap, then insert between
+		{"abcdef", "badcfe", 3},
+		{"naïve", "naive", 1},
+		{"café", "cafe", 1},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := editDistance(tt.b, tt.a); got != tt.want {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.b, tt.a, got, tt.want)
+		}
+	}
+}
+
+func TestMaxDistance(t *testing.T) {
+	for n, want := range map[int]int{0: 0, 2: 0, 3: 1, 4: 1, 5: 2, 8: 2, 9: 3, 20: 3} {
+		if got := maxDistance(n); got != want {
+			t.Errorf("maxDistance(%d) = %d, want %d", n, got, want)
+		}
+	}
+}
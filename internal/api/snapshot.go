@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/user/go-brew-search/internal/cache"
+)
+
+// LoadSnapshot uses package data from files instead of the network and
+// turns on offline mode. Each file is either formula.json or cask.json as
+// served by the Homebrew API, or a formulae.bin or casks.bin entry copied
+// from the cache directory. Types without a snapshot come from the cache.
+func (c *Client) LoadSnapshot(paths ...string) error {
+	for _, path := range paths {
+		packages, stored, err := c.readSnapshot(path)
+		if err != nil {
+			return fmt.Errorf("failed to read snapshot %s: %w", path, err)
+		}
+		if len(packages) == 0 {
+			return fmt.Errorf("snapshot %s contains no formulae or casks", path)
+		}
+
+		key := "formulae"
+		if packages[0].Type == "cask" {
+			key = "casks"
+		}
+		c.snapshot[key] = packages
+		c.snapshotTime[key] = stored
+	}
+
+	c.offline = true
+	return nil
+}
+
+// readSnapshot returns the packages in a snapshot file and when they were
+// downloaded. Raw API files don't record that, so their modification time
+// is used.
+func (c *Client) readSnapshot(path string) ([]Package, time.Time, error) {
+	if filepath.Ext(path) == ".bin" {
+		var packages []Package
+		stored, err := cache.ReadFile(path, cacheSchema, &packages)
+		return packages, stored, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var data []map[string]any
+	if err := json.Unmarshal(content, &data); err != nil {
+		return nil, time.Time{}, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	// Casks are identified by token, formulae by name
+	if len(data) > 0 {
+		if _, ok := data[0]["token"].(string); ok {
+			return c.parseCasks(data), info.ModTime(), nil
+		}
+	}
+	return c.parseFormulae(data), info.ModTime(), nil
+}
@@ -0,0 +1,43 @@
+package brew
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// DryRunner prints the commands that Run would execute instead of running
+// them. Read-only Output commands are passed to Query so that reports still
+// reflect the real state; without a Query they are printed too.
+type DryRunner struct {
+	Path  string // brew binary shown in the printed commands
+	Out   io.Writer
+	Query Runner
+}
+
+// NewDryRunner returns a dry-run runner that prints to stdout and answers
+// queries with query.
+func NewDryRunner(path string, query Runner) *DryRunner {
+	return &DryRunner{
+		Path:  path,
+		Out:   os.Stdout,
+		Query: query,
+	}
+}
+
+func (d *DryRunner) Output(args ...string) ([]byte, error) {
+	if d.Query != nil {
+		return d.Query.Output(args...)
+	}
+	d.print(args)
+	return nil, nil
+}
+
+func (d *DryRunner) Run(args ...string) error {
+	d.print(args)
+	return nil
+}
+
+func (d *DryRunner) print(args []string) {
+	fmt.Fprintf(d.Out, "   %s\n", FormatCommand(d.Path, args...))
+}
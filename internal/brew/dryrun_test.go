@@ -0,0 +1,44 @@
+package brew
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDryRunner(t *testing.T) {
+	var out bytes.Buffer
+	query := NewFakeRunner().On("list --formula -1", Response{Stdout: "jq\n"})
+	dry := &DryRunner{Path: "/opt/homebrew/bin/brew", Out: &out, Query: query}
+
+	if err := dry.Run("install", "--cask", "visual-studio-code", "it's"); err != nil {
+		t.Fatal(err)
+	}
+	formulae, err := dry.Output("list", "--formula", "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "   /opt/homebrew/bin/brew install --cask visual-studio-code 'it'\\''s'\n"
+	if got := out.String(); got != want {
+		t.Errorf("printed %q, want %q", got, want)
+	}
+	if string(formulae) != "jq\n" {
+		t.Errorf("Output = %q, want the query runner's answer", formulae)
+	}
+	if calls := query.Calls(); len(calls) != 1 {
+		t.Errorf("query runner got %d calls, want only the read-only one", len(calls))
+	}
+}
+
+func TestDryRunnerWithoutQuery(t *testing.T) {
+	var out bytes.Buffer
+	dry := &DryRunner{Out: &out}
+
+	data, err := dry.Output("list", "--cask", "-1")
+	if err != nil || len(data) != 0 {
+		t.Errorf("Output = %q, %v, want no output", data, err)
+	}
+	if want := "   brew list --cask -1\n"; out.String() != want {
+		t.Errorf("printed %q, want %q", out.String(), want)
+	}
+}
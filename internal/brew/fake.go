@@ -0,0 +1,95 @@
+package brew
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Response is a scripted result for FakeRunner
+type Response struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// Call is a command recorded by FakeRunner
+type Call struct {
+	Args        []string
+	Interactive bool // made through Run rather than Output
+}
+
+// FakeRunner records commands and answers them with scripted responses
+// instead of running brew. Commands without a response succeed with no
+// output.
+type FakeRunner struct {
+	// Stdout and Stderr receive the scripted output of Run calls when set
+	Stdout io.Writer
+	Stderr io.Writer
+
+	mu        sync.Mutex
+	responses map[string]Response
+	calls     []Call
+}
+
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{
+		responses: make(map[string]Response),
+	}
+}
+
+// On scripts the response for a command, given as its space-separated
+// arguments (e.g. "list --cask -1").
+func (f *FakeRunner) On(command string, resp Response) *FakeRunner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.responses[command] = resp
+	return f
+}
+
+// Calls returns the commands run so far, in order
+func (f *FakeRunner) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	calls := make([]Call, len(f.calls))
+	copy(calls, f.calls)
+	return calls
+}
+
+func (f *FakeRunner) Output(args ...string) ([]byte, error) {
+	resp := f.record(args, false)
+	return []byte(resp.Stdout), resp.err(args)
+}
+
+func (f *FakeRunner) Run(args ...string) error {
+	resp := f.record(args, true)
+
+	if f.Stdout != nil && resp.Stdout != "" {
+		fmt.Fprint(f.Stdout, resp.Stdout)
+	}
+	if f.Stderr != nil && resp.Stderr != "" {
+		fmt.Fprint(f.Stderr, resp.Stderr)
+	}
+	return resp.err(args)
+}
+
+func (f *FakeRunner) record(args []string, interactive bool) Response {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, Call{
+		Args:        append([]string(nil), args...),
+		Interactive: interactive,
+	})
+	return f.responses[strings.Join(args, " ")]
+}
+
+func (r Response) err(args []string) error {
+	if r.ExitCode == 0 {
+		return nil
+	}
+	return &ExitError{Args: args, Code: r.ExitCode, Stderr: strings.TrimSpace(r.Stderr)}
+}
@@ -0,0 +1,175 @@
+package brew
+
+import "strings"
+
+// InstallStatus is the outcome of installing one package
+type InstallStatus int
+
+const (
+	StatusInstalled InstallStatus = iota
+	StatusAlreadyInstalled
+	StatusFailed
+)
+
+func (s InstallStatus) String() string {
+	switch s {
+	case StatusInstalled:
+		return "installed"
+	case StatusAlreadyInstalled:
+		return "already installed"
+	default:
+		return "failed"
+	}
+}
+
+// InstallResult reports what happened to one requested package
+type InstallResult struct {
+	Name   string
+	Cask   bool
+	Status InstallStatus
+	Err    error
+}
+
+// Installer installs formulae and casks in batches: one brew install for all
+// formulae and one for all casks, so brew can resolve dependencies and fetch
+// downloads together.
+type Installer struct {
+	Runner Runner
+	// Fallback retries each package on its own when a batch fails, so one
+	// broken package doesn't block the rest.
+	Fallback bool
+}
+
+// Install installs the given packages and reports the outcome of each, in
+// the order requested (formulae first).
+func (in *Installer) Install(formulae, casks []string) []InstallResult {
+	var results []InstallResult
+	if len(formulae) > 0 {
+		results = append(results, in.installBatch(formulae, false)...)
+	}
+	if len(casks) > 0 {
+		results = append(results, in.installBatch(casks, true)...)
+	}
+	return results
+}
+
+func (in *Installer) installBatch(names []string, cask bool) []InstallResult {
+	results := make([]InstallResult, len(names))
+	for i, name := range names {
+		results[i] = InstallResult{Name: name, Cask: cask}
+	}
+
+	// Skip what's already there so the summary can tell it apart
+	before, err := in.installed(cask)
+	if err != nil {
+		before = map[string]bool{}
+	}
+
+	var pending []int
+	for i, name := range names {
+		if before[installedKey(name)] {
+			results[i].Status = StatusAlreadyInstalled
+		} else {
+			pending = append(pending, i)
+		}
+	}
+	if len(pending) == 0 {
+		return results
+	}
+
+	batchErr := in.Runner.Run(installArgs(cask, namesAt(names, pending)...)...)
+	if batchErr == nil {
+		for _, i := range pending {
+			results[i].Status = StatusInstalled
+		}
+		return results
+	}
+
+	// The batch failed part way; find out which packages made it
+	after, err := in.installed(cask)
+	if err != nil {
+		after = map[string]bool{}
+	}
+
+	for _, i := range pending {
+		if after[installedKey(names[i])] {
+			results[i].Status = StatusInstalled
+			continue
+		}
+
+		if !in.Fallback {
+			results[i].Status = StatusFailed
+			results[i].Err = batchErr
+			continue
+		}
+
+		if err := in.Runner.Run(installArgs(cask, names[i])...); err != nil {
+			results[i].Status = StatusFailed
+			results[i].Err = err
+		} else {
+			results[i].Status = StatusInstalled
+		}
+	}
+
+	return results
+}
+
+func (in *Installer) installed(cask bool) (map[string]bool, error) {
+	var names []string
+	var err error
+	if cask {
+		names, err = InstalledCasks(in.Runner)
+	} else {
+		names, err = InstalledFormulae(in.Runner)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[installedKey(name)] = true
+	}
+	return set, nil
+}
+
+// installedKey is the name brew list reports for a package: tap-qualified
+// names are listed by their short name.
+func installedKey(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.ToLower(name)
+}
+
+func installArgs(cask bool, names ...string) []string {
+	args := []string{"install"}
+	if cask {
+		args = append(args, "--cask")
+	}
+	return append(args, names...)
+}
+
+func namesAt(names []string, indices []int) []string {
+	result := make([]string, len(indices))
+	for i, idx := range indices {
+		result[i] = names[idx]
+	}
+	return result
+}
+
+// Upgrade upgrades the given formulae and casks with one brew upgrade for
+// each type
+func Upgrade(r Runner, formulae, casks []string) error {
+	if len(formulae) > 0 {
+		if err := r.Run(append([]string{"upgrade", "--formula"}, formulae...)...); err != nil {
+			return err
+		}
+	}
+	if len(casks) > 0 {
+		if err := r.Run(append([]string{"upgrade", "--cask"}, casks...)...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package brew
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInstallerBatch(t *testing.T) {
+	fake := NewFakeRunner().
+		On("list --formula -1", Response{Stdout: "jq\n"}).
+		On("list --cask -1", Response{Stdout: ""})
+
+	in := &Installer{Runner: fake, Fallback: true}
+	results := in.Install([]string{"jq", "ripgrep", "user/tap/fd"}, []string{"firefox"})
+
+	want := []InstallResult{
+		{Name: "jq", Status: StatusAlreadyInstalled},
+		{Name: "ripgrep", Status: StatusInstalled},
+		{Name: "user/tap/fd", Status: StatusInstalled},
+		{Name: "firefox", Cask: true, Status: StatusInstalled},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("results = %+v, want %+v", results, want)
+	}
+
+	var installs []string
+	for _, call := range fake.Calls() {
+		if call.Args[0] == "install" {
+			installs = append(installs, strings.Join(call.Args, " "))
+		}
+	}
+	wantInstalls := []string{"install ripgrep user/tap/fd", "install --cask firefox"}
+	if !reflect.DeepEqual(installs, wantInstalls) {
+		t.Errorf("installs = %q, want %q", installs, wantInstalls)
+	}
+}
+
+func TestInstallerFallback(t *testing.T) {
+	newFake := func() *FakeRunner {
+		return NewFakeRunner().
+			On("install jq broken ripgrep", Response{ExitCode: 1, Stderr: "Error: broken"}).
+			On("install broken", Response{ExitCode: 1, Stderr: "Error: no formula broken"})
+	}
+
+	t.Run("retries each package", func(t *testing.T) {
+		fake := newFake()
+		in := &Installer{Runner: fake, Fallback: true}
+		results := in.Install([]string{"jq", "broken", "ripgrep"}, nil)
+
+		statuses := []InstallStatus{StatusInstalled, StatusFailed, StatusInstalled}
+		for i, r := range results {
+			if r.Status != statuses[i] {
+				t.Errorf("%s: status %v, want %v", r.Name, r.Status, statuses[i])
+			}
+		}
+		var exitErr *ExitError
+		if !errors.As(results[1].Err, &exitErr) || !reflect.DeepEqual(exitErr.Args, []string{"install", "broken"}) {
+			t.Errorf("broken: err = %v, want the error of its own install", results[1].Err)
+		}
+
+		var retried []string
+		for _, call := range fake.Calls() {
+			if call.Args[0] == "install" && len(call.Args) == 2 {
+				retried = append(retried, call.Args[1])
+			}
+		}
+		if want := []string{"jq", "broken", "ripgrep"}; !reflect.DeepEqual(retried, want) {
+			t.Errorf("retried %q, want %q", retried, want)
+		}
+	})
+
+	t.Run("without fallback", func(t *testing.T) {
+		fake := newFake()
+		in := &Installer{Runner: fake}
+		results := in.Install([]string{"jq", "broken", "ripgrep"}, nil)
+
+		for _, r := range results {
+			if r.Status != StatusFailed || r.Err == nil {
+				t.Errorf("%s: status %v, err %v, want the batch failure", r.Name, r.Status, r.Err)
+			}
+		}
+		for _, call := range fake.Calls() {
+			if call.Args[0] == "install" && len(call.Args) == 2 {
+				t.Errorf("unexpected retry %q", call.Args)
+			}
+		}
+	})
+}
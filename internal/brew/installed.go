@@ -0,0 +1,109 @@
+package brew
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Installed describes what Homebrew reports as present on this machine.
+type Installed struct {
+	Leaves   []string // formulae installed on request
+	Formulae []string // every installed formula, including dependencies
+	Casks    []string
+	Taps     []string
+}
+
+// LoadInstalled queries brew for installed formulae, casks and taps.
+func LoadInstalled(r Runner) (*Installed, error) {
+	leaves, err := lines(r, "leaves", "--installed-on-request")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list leaves: %w", err)
+	}
+
+	formulae, err := InstalledFormulae(r)
+	if err != nil {
+		return nil, err
+	}
+
+	casks, err := InstalledCasks(r)
+	if err != nil {
+		return nil, err
+	}
+
+	taps, err := lines(r, "tap")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list taps: %w", err)
+	}
+
+	return &Installed{
+		Leaves:   leaves,
+		Formulae: formulae,
+		Casks:    casks,
+		Taps:     taps,
+	}, nil
+}
+
+// InstalledFormulae lists every installed formula, including dependencies
+func InstalledFormulae(r Runner) ([]string, error) {
+	formulae, err := lines(r, "list", "--formula", "-1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list formulae: %w", err)
+	}
+	return formulae, nil
+}
+
+// InstalledCasks lists every installed cask
+func InstalledCasks(r Runner) ([]string, error) {
+	casks, err := lines(r, "list", "--cask", "-1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list casks: %w", err)
+	}
+	return casks, nil
+}
+
+// InstalledVersions maps installed formulae and casks to the versions brew
+// list --versions reports for them. A formula can have several versions
+// installed side by side.
+func InstalledVersions(r Runner) (formulae, casks map[string][]string, err error) {
+	formulae, err = versions(r, "--formula")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list formula versions: %w", err)
+	}
+	casks, err = versions(r, "--cask")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list cask versions: %w", err)
+	}
+	return formulae, casks, nil
+}
+
+func versions(r Runner, typeFlag string) (map[string][]string, error) {
+	lines, err := lines(r, "list", "--versions", typeFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]string, len(lines))
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) > 1 {
+			result[fields[0]] = fields[1:]
+		}
+	}
+	return result, nil
+}
+
+func lines(r Runner, args ...string) ([]string, error) {
+	out, err := r.Output(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, line := range strings.Split(string(out), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,48 @@
+package brew
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadInstalled(t *testing.T) {
+	fake := NewFakeRunner().
+		On("leaves --installed-on-request", Response{Stdout: "jq\nripgrep\n"}).
+		On("list --formula -1", Response{Stdout: "jq\noniguruma\nripgrep\n"}).
+		On("list --cask -1", Response{Stdout: "firefox\n\n"}).
+		On("tap", Response{Stdout: "homebrew/core\nuser/tools\n"})
+
+	installed, err := LoadInstalled(fake)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Installed{
+		Leaves:   []string{"jq", "ripgrep"},
+		Formulae: []string{"jq", "oniguruma", "ripgrep"},
+		Casks:    []string{"firefox"},
+		Taps:     []string{"homebrew/core", "user/tools"},
+	}
+	if !reflect.DeepEqual(installed, want) {
+		t.Errorf("LoadInstalled = %+v, want %+v", installed, want)
+	}
+	for _, call := range fake.Calls() {
+		if call.Interactive {
+			t.Errorf("%q was run interactively", call.Args)
+		}
+	}
+}
+
+func TestLoadInstalledError(t *testing.T) {
+	fake := NewFakeRunner().
+		On("list --cask -1", Response{ExitCode: 1, Stderr: "Error: permission denied\n"})
+
+	_, err := LoadInstalled(fake)
+	if err == nil {
+		t.Fatal("LoadInstalled succeeded, want an error")
+	}
+	if msg := err.Error(); !strings.Contains(msg, "failed to list casks") || !strings.Contains(msg, "permission denied") {
+		t.Errorf("err = %q, want it to name the failed listing and brew's message", msg)
+	}
+}
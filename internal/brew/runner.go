@@ -0,0 +1,121 @@
+package brew
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// DefaultPath is the brew binary used when no path is configured
+const DefaultPath = "brew"
+
+// Runner executes brew commands. Everything that shells out to Homebrew goes
+// through a Runner so it can be replaced by FakeRunner or DryRunner.
+type Runner interface {
+	// Output runs a brew command that only reads state and returns its
+	// standard output.
+	Output(args ...string) ([]byte, error)
+	// Run runs a brew command attached to the terminal.
+	Run(args ...string) error
+}
+
+// ExitError reports a brew command that exited with a non-zero status
+type ExitError struct {
+	Args   []string
+	Code   int
+	Stderr string
+}
+
+func (e *ExitError) Error() string {
+	msg := fmt.Sprintf("brew %s: exit status %d", strings.Join(e.Args, " "), e.Code)
+	if e.Stderr != "" {
+		msg += ": " + e.Stderr
+	}
+	return msg
+}
+
+// ExecRunner runs a real brew binary
+type ExecRunner struct {
+	Path   string // brew binary; DefaultPath when empty
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// NewExecRunner returns a runner for the brew binary at path, attached to
+// the process's standard streams.
+func NewExecRunner(path string) *ExecRunner {
+	return &ExecRunner{
+		Path:   path,
+		Stdin:  os.Stdin,
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+}
+
+func (r *ExecRunner) binary() string {
+	if r.Path == "" {
+		return DefaultPath
+	}
+	return r.Path
+}
+
+func (r *ExecRunner) Output(args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command(r.binary(), args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	return out, wrapError(args, err, strings.TrimSpace(stderr.String()))
+}
+
+func (r *ExecRunner) Run(args ...string) error {
+	cmd := exec.Command(r.binary(), args...)
+	cmd.Stdin = r.Stdin
+	cmd.Stdout = r.Stdout
+	cmd.Stderr = r.Stderr
+
+	return wrapError(args, cmd.Run(), "")
+}
+
+func wrapError(args []string, err error, stderr string) error {
+	if err == nil {
+		return nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return &ExitError{Args: args, Code: exitErr.ExitCode(), Stderr: stderr}
+	}
+	return fmt.Errorf("brew %s: %w", strings.Join(args, " "), err)
+}
+
+// FormatCommand renders a brew invocation as a shell command line
+func FormatCommand(path string, args ...string) string {
+	if path == "" {
+		path = DefaultPath
+	}
+
+	parts := make([]string, 0, len(args)+1)
+	for _, arg := range append([]string{path}, args...) {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			strings.ContainsRune("-_./@:=+,%", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
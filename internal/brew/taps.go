@@ -0,0 +1,47 @@
+package brew
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TapPackage is a formula or cask provided by an installed tap
+type TapPackage struct {
+	Name string // tap-qualified, e.g. "user/tap/tool"
+	Tap  string
+	Cask bool
+}
+
+type tapInfo struct {
+	Name         string   `json:"name"`
+	FormulaNames []string `json:"formula_names"`
+	CaskTokens   []string `json:"cask_tokens"`
+}
+
+// TapPackages lists the formulae and casks in the given installed taps
+func TapPackages(r Runner, taps []string) ([]TapPackage, error) {
+	if len(taps) == 0 {
+		return nil, nil
+	}
+
+	out, err := r.Output(append([]string{"tap-info", "--json"}, taps...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read taps: %w", err)
+	}
+
+	var infos []tapInfo
+	if err := json.Unmarshal(out, &infos); err != nil {
+		return nil, fmt.Errorf("failed to parse tap info: %w", err)
+	}
+
+	var packages []TapPackage
+	for _, info := range infos {
+		for _, name := range info.FormulaNames {
+			packages = append(packages, TapPackage{Name: name, Tap: info.Name})
+		}
+		for _, name := range info.CaskTokens {
+			packages = append(packages, TapPackage{Name: name, Tap: info.Name, Cask: true})
+		}
+	}
+	return packages, nil
+}
@@ -0,0 +1,188 @@
+package brew
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Unversioned is the version casks without one report; it is never outdated
+const Unversioned = "latest"
+
+// preRelease orders the words that mark a version as coming before the
+// release it names, e.g. "1.0rc1" before "1.0"
+var preRelease = map[string]int{
+	"alpha": 1,
+	"beta":  2,
+	"pre":   3,
+	"rc":    4,
+}
+
+// CompareVersions compares two Homebrew versions and returns -1, 0 or +1.
+// Numbers compare numerically and words alphabetically, with pre-release
+// words before the release. Cask versions such as "1.2.3,456" compare each
+// comma-separated part in turn, and a formula revision such as the "_1" in
+// "1.2.3_1" only breaks ties.
+func CompareVersions(a, b string) int {
+	aParts, bParts := strings.Split(a, ","), strings.Split(b, ",")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var x, y string
+		if i < len(aParts) {
+			x = aParts[i]
+		}
+		if i < len(bParts) {
+			y = bParts[i]
+		}
+		if c := compareRevisioned(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareRevisioned compares versions that may carry a "_N" revision
+func compareRevisioned(a, b string) int {
+	aBase, aRev := splitRevision(a)
+	bBase, bRev := splitRevision(b)
+	if c := compareTokens(tokenize(aBase), tokenize(bBase)); c != 0 {
+		return c
+	}
+	return compareInts(aRev, bRev)
+}
+
+func splitRevision(version string) (string, int) {
+	i := strings.LastIndex(version, "_")
+	if i < 0 {
+		return version, 0
+	}
+	rev, err := strconv.Atoi(version[i+1:])
+	if err != nil || rev < 0 {
+		return version, 0
+	}
+	return version[:i], rev
+}
+
+// versionToken is a run of digits or of letters
+type versionToken struct {
+	text    string
+	numeric bool
+}
+
+// tokenize splits a version into numbers and words, dropping separators
+// such as "." and "-"
+func tokenize(version string) []versionToken {
+	var tokens []versionToken
+	for i := 0; i < len(version); {
+		c := version[i]
+		switch {
+		case isDigit(c):
+			j := i
+			for j < len(version) && isDigit(version[j]) {
+				j++
+			}
+			tokens = append(tokens, versionToken{text: strings.TrimLeft(version[i:j], "0"), numeric: true})
+			i = j
+		case isLetter(c):
+			j := i
+			for j < len(version) && isLetter(version[j]) {
+				j++
+			}
+			tokens = append(tokens, versionToken{text: strings.ToLower(version[i:j])})
+			i = j
+		default:
+			i++
+		}
+	}
+	return tokens
+}
+
+func compareTokens(a, b []versionToken) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		switch {
+		case i >= len(a):
+			if c := -compareMissing(b[i]); c != 0 {
+				return c
+			}
+			continue
+		case i >= len(b):
+			if c := compareMissing(a[i]); c != 0 {
+				return c
+			}
+			continue
+		}
+
+		x, y := a[i], b[i]
+		switch {
+		case x.numeric && y.numeric:
+			// Leading zeros are trimmed, so the longer number is larger
+			if len(x.text) != len(y.text) {
+				return compareInts(len(x.text), len(y.text))
+			}
+			if c := strings.Compare(x.text, y.text); c != 0 {
+				return c
+			}
+		case x.numeric:
+			return 1
+		case y.numeric:
+			return -1
+		default:
+			if c := compareWords(x.text, y.text); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// compareMissing compares a token with the end of a shorter version:
+// "1.0.0" equals "1.0", "1.0rc1" comes before it and "1.0.1" after it
+func compareMissing(t versionToken) int {
+	switch {
+	case t.numeric && t.text == "":
+		return 0
+	case !t.numeric && preRelease[t.text] > 0:
+		return -1
+	}
+	return 1
+}
+
+func compareWords(a, b string) int {
+	pa, pb := preRelease[a], preRelease[b]
+	switch {
+	case pa > 0 && pb > 0:
+		return compareInts(pa, pb)
+	case pa > 0:
+		return -1
+	case pb > 0:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// NewestVersion returns the newest of the given versions
+func NewestVersion(versions []string) string {
+	newest := ""
+	for _, v := range versions {
+		if newest == "" || CompareVersions(v, newest) > 0 {
+			newest = v
+		}
+	}
+	return newest
+}
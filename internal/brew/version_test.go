@@ -0,0 +1,51 @@
+package brew
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.0.0", 0},
+		{"01.2", "1.2", 0},
+		{"1.2", "1.10", -1},
+		{"1.10", "1.9", 1},
+		{"2", "1.99.99", 1},
+		{"1.0.1", "1.0", 1},
+		{"1.0rc1", "1.0", -1},
+		{"1.0-beta", "1.0", -1},
+		{"1.0alpha", "1.0beta", -1},
+		{"1.0beta2", "1.0rc1", -1},
+		{"1.0rc1", "1.0rc2", -1},
+		{"1.0RC1", "1.0rc1", 0},
+		{"1.0a", "1.0b", -1},
+		{"1.0a", "1.0", 1},
+		{"1.0.1", "1.0a", 1},
+		{"1.2.3_1", "1.2.3", 1},
+		{"1.2.3_2", "1.2.3_10", -1},
+		{"1.2.4", "1.2.3_5", 1},
+		{"1.2.3,456", "1.2.3,457", -1},
+		{"1.2.3,456", "1.2.3", 1},
+		{"2.0,1", "1.9,99", 1},
+		{"2024.01.15", "2023.12.31", 1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := CompareVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestNewestVersion(t *testing.T) {
+	if got := NewestVersion([]string{"1.9", "1.10_1", "1.10", "1.10rc1"}); got != "1.10_1" {
+		t.Errorf("NewestVersion = %q, want 1.10_1", got)
+	}
+	if got := NewestVersion(nil); got != "" {
+		t.Errorf("NewestVersion(nil) = %q, want empty", got)
+	}
+}
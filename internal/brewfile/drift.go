@@ -0,0 +1,145 @@
+package brewfile
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/user/go-brew-search/internal/brew"
+)
+
+// Drift is the difference between a Brewfile and the installed state.
+type Drift struct {
+	Unlisted []Entry // installed on request but not in the Brewfile
+	Missing  []Entry // in the Brewfile but not installed
+}
+
+// InSync reports whether there is no drift at all.
+func (d Drift) InSync() bool {
+	return len(d.Unlisted) == 0 && len(d.Missing) == 0
+}
+
+// defaultTaps are always present and never need to be listed.
+var defaultTaps = map[string]bool{
+	"homebrew/core": true,
+	"homebrew/cask": true,
+}
+
+// ComputeDrift compares Brewfile entries with what brew reports as installed.
+func ComputeDrift(entries []Entry, installed *brew.Installed) Drift {
+	listed := map[string]map[string]bool{
+		"brew": {},
+		"cask": {},
+		"tap":  {},
+	}
+	for _, e := range entries {
+		if e.IsPackage() {
+			addName(listed[e.Kind], e.Kind, e.Name)
+		}
+	}
+
+	present := map[string]map[string]bool{
+		"brew": toSet("brew", installed.Formulae),
+		"cask": toSet("cask", installed.Casks),
+		"tap":  toSet("tap", installed.Taps),
+	}
+
+	var drift Drift
+
+	unlisted := func(kind string, names []string) {
+		for _, name := range names {
+			if kind == "tap" && defaultTaps[NormalizeName(kind, name)] {
+				continue
+			}
+			if !hasName(listed[kind], kind, name) {
+				drift.Unlisted = append(drift.Unlisted, Entry{Kind: kind, Name: name})
+			}
+		}
+	}
+	unlisted("tap", installed.Taps)
+	unlisted("brew", installed.Leaves)
+	unlisted("cask", installed.Casks)
+
+	for _, e := range entries {
+		if e.IsPackage() && !hasName(present[e.Kind], e.Kind, e.Name) {
+			drift.Missing = append(drift.Missing, e)
+		}
+	}
+
+	sortEntries(drift.Unlisted)
+	sortEntries(drift.Missing)
+
+	return drift
+}
+
+// NormalizeName returns the form of a package name used for comparisons:
+// lowercase, with the implicit homebrew/core and homebrew/cask taps removed.
+// Formulae from third-party taps keep their tap prefix.
+func NormalizeName(kind, name string) string {
+	name = strings.ToLower(name)
+	switch kind {
+	case "brew":
+		name = strings.TrimPrefix(name, "homebrew/core/")
+	case "cask":
+		name = strings.TrimPrefix(name, "homebrew/cask/")
+	case "tap":
+		name = strings.Replace(name, "/homebrew-", "/", 1)
+	}
+	return name
+}
+
+func toSet(kind string, names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		addName(set, kind, name)
+	}
+	return set
+}
+
+// addName records name in set. Tap-qualified formulae and casks are also
+// recorded by their short name, since brew reports them either way.
+func addName(set map[string]bool, kind, name string) {
+	key := NormalizeName(kind, name)
+	set[key] = true
+	if kind != "tap" {
+		set[shortName(key)] = true
+	}
+}
+
+func hasName(set map[string]bool, kind, name string) bool {
+	key := NormalizeName(kind, name)
+	if set[key] {
+		return true
+	}
+	return kind != "tap" && set[shortName(key)]
+}
+
+func shortName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+var kindOrder = map[string]int{"tap": 0, "brew": 1, "cask_args": 2, "cask": 3}
+
+// sortEntries orders entries by kind and name. Other directives go last and
+// keep their original order.
+func sortEntries(entries []Entry) {
+	order := func(kind string) int {
+		if o, ok := kindOrder[kind]; ok {
+			return o
+		}
+		return len(kindOrder)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := order(entries[i].Kind), order(entries[j].Kind)
+		if a != b {
+			return a < b
+		}
+		if a == len(kindOrder) {
+			return false
+		}
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+}
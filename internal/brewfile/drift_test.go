@@ -0,0 +1,79 @@
+package brewfile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/user/go-brew-search/internal/brew"
+)
+
+func TestComputeDrift(t *testing.T) {
+	installed := &brew.Installed{
+		Leaves:   []string{"jq", "wget", "fd"},
+		Formulae: []string{"jq", "oniguruma", "wget", "fd"},
+		Casks:    []string{"firefox", "slack"},
+		Taps:     []string{"homebrew/core", "homebrew/cask", "user/tools", "other/extra"},
+	}
+
+	entries, err := Parse(strings.NewReader(`tap "user/homebrew-tools"
+brew "JQ"
+brew "user/tools/fd"
+brew "ripgrep", args: ["HEAD"]
+cask "homebrew/cask/firefox"
+cask "iterm2"
+mas "Xcode", id: 497799835
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	drift := ComputeDrift(entries, installed)
+	if drift.InSync() {
+		t.Fatal("InSync = true, want drift")
+	}
+
+	names := func(entries []Entry) []string {
+		var result []string
+		for _, e := range entries {
+			result = append(result, e.Kind+" "+e.Name)
+		}
+		return result
+	}
+	if got, want := names(drift.Unlisted), []string{"tap other/extra", "brew wget", "cask slack"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unlisted = %q, want %q", got, want)
+	}
+	if got, want := names(drift.Missing), []string{"brew ripgrep", "cask iterm2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Missing = %q, want %q", got, want)
+	}
+}
+
+func TestComputeDriftInSync(t *testing.T) {
+	installed := &brew.Installed{
+		Leaves:   []string{"jq"},
+		Formulae: []string{"jq", "oniguruma"},
+		Taps:     []string{"homebrew/core"},
+	}
+	entries := []Entry{{Kind: "brew", Name: "jq"}}
+
+	if drift := ComputeDrift(entries, installed); !drift.InSync() {
+		t.Errorf("drift = %+v, want none", drift)
+	}
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		kind, name, want string
+	}{
+		{"brew", "JQ", "jq"},
+		{"brew", "homebrew/core/jq", "jq"},
+		{"brew", "user/tools/fd", "user/tools/fd"},
+		{"cask", "homebrew/cask/Firefox", "firefox"},
+		{"tap", "User/homebrew-Tools", "user/tools"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeName(tt.kind, tt.name); got != tt.want {
+			t.Errorf("NormalizeName(%q, %q) = %q, want %q", tt.kind, tt.name, got, tt.want)
+		}
+	}
+}
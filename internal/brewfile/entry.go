@@ -0,0 +1,174 @@
+package brewfile
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Entry is a single declaration in a Brewfile.
+type Entry struct {
+	Kind    string   // "brew", "cask", "tap", or another directive such as "mas"
+	Name    string   // package or tap name; empty for other directives
+	Options string   // anything after the name, e.g. `, args: ["HEAD"]`
+	Comment string   // trailing comment
+	Doc     []string // comment lines since the previous entry; "" marks blank lines between them
+	Raw     string   // the original line, used to render other directives
+	Line    int
+}
+
+// IsPackage reports whether the entry is a brew, cask or tap declaration
+func (e Entry) IsPackage() bool {
+	return e.Kind == "brew" || e.Kind == "cask" || e.Kind == "tap"
+}
+
+// file is a parsed Brewfile with the comments that belong to no entry
+type file struct {
+	header  []string // comment blocks at the top of the file
+	entries []Entry
+	trailer []string // comments after the last entry
+}
+
+// Parse reads Brewfile entries from r. Full-line comments are kept as the
+// Doc of the entry after them, except for the header at the top of the
+// file and comments after the last entry.
+func Parse(r io.Reader) ([]Entry, error) {
+	f, err := parseFile(r)
+	return f.entries, err
+}
+
+// parseFile reads a Brewfile, keeping every comment line
+func parseFile(r io.Reader) (file, error) {
+	var f file
+	var doc []string
+	started := false
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		raw := strings.TrimSpace(scanner.Text())
+
+		// Remember comments and the blank lines between them for the next
+		// entry
+		if raw == "" {
+			if len(doc) > 0 && doc[len(doc)-1] != "" {
+				doc = append(doc, "")
+			}
+			continue
+		}
+		if strings.HasPrefix(raw, "#") {
+			doc = append(doc, raw)
+			continue
+		}
+
+		line := raw
+		var comment string
+		if i := commentIndex(line); i >= 0 {
+			comment = strings.TrimSpace(strings.TrimPrefix(line[i:], "#"))
+			line = strings.TrimSpace(line[:i])
+		}
+
+		if !started {
+			f.header, doc = splitHeader(doc)
+			started = true
+		}
+
+		kind, rest, _ := strings.Cut(line, " ")
+		e := Entry{
+			Kind:    kind,
+			Comment: comment,
+			Doc:     doc,
+			Raw:     raw,
+			Line:    lineNo,
+		}
+		doc = nil
+
+		if e.IsPackage() {
+			name, options := splitName(strings.TrimSpace(rest))
+			if name == "" {
+				continue
+			}
+			e.Name = name
+			e.Options = options
+		}
+
+		f.entries = append(f.entries, e)
+	}
+
+	if started {
+		f.trailer = doc
+	} else {
+		f.header = doc
+	}
+	return f, scanner.Err()
+}
+
+// splitHeader separates the file header from the comments above the first
+// entry. The header ends at the first generated comment, such as a section
+// title, or else at the last blank line. Without either, all of it is the
+// header, so it stays at the top when the entries are sorted.
+func splitHeader(doc []string) (header, rest []string) {
+	end := len(doc)
+	for i, line := range doc {
+		if generatedComment(line) {
+			end = i
+			break
+		}
+		if line == "" {
+			end = i
+		}
+	}
+
+	header, rest = doc[:end:end], doc[end:]
+	for len(rest) > 0 && rest[0] == "" {
+		rest = rest[1:]
+	}
+	if len(header) == 0 {
+		header = nil
+	}
+	if len(rest) == 0 {
+		rest = nil
+	}
+	return header, rest
+}
+
+// splitName separates the quoted name at the start of a declaration from
+// the options after it
+func splitName(s string) (name, options string) {
+	if s == "" {
+		return "", ""
+	}
+
+	if quote := s[0]; quote == '"' || quote == '\'' {
+		if end := strings.IndexByte(s[1:], quote); end >= 0 {
+			return s[1 : end+1], strings.TrimSpace(s[end+2:])
+		}
+		return strings.Trim(s, `"'`), ""
+	}
+
+	name, options, _ = strings.Cut(s, ",")
+	if options != "" {
+		options = "," + options
+	}
+	return strings.TrimSpace(name), strings.TrimSpace(options)
+}
+
+// commentIndex finds a trailing # comment outside of quotes
+func commentIndex(line string) int {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return i
+		}
+	}
+	return -1
+}
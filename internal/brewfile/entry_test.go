@@ -0,0 +1,117 @@
+package brewfile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	entries, err := Parse(strings.NewReader(`# Brewfile
+tap "homebrew/cask-fonts"
+
+# jq is used by scripts
+brew "jq" # JSON on the command line
+
+# --- dev tools ---
+
+# search
+brew 'ripgrep', args: ["HEAD"]
+  cask "firefox"
+brew "url#fragment" # a # in quotes is not a comment
+mas "Xcode", id: 497799835
+brew
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Entry{
+		{Kind: "tap", Name: "homebrew/cask-fonts", Raw: `tap "homebrew/cask-fonts"`, Line: 2},
+		{Kind: "brew", Name: "jq", Comment: "JSON on the command line", Doc: []string{"# jq is used by scripts"}, Raw: `brew "jq" # JSON on the command line`, Line: 5},
+		{Kind: "brew", Name: "ripgrep", Options: `, args: ["HEAD"]`, Doc: []string{"# --- dev tools ---", "", "# search"}, Raw: `brew 'ripgrep', args: ["HEAD"]`, Line: 10},
+		{Kind: "cask", Name: "firefox", Raw: `cask "firefox"`, Line: 11},
+		{Kind: "brew", Name: "url#fragment", Comment: "a # in quotes is not a comment", Raw: `brew "url#fragment" # a # in quotes is not a comment`, Line: 12},
+		{Kind: "mas", Raw: `mas "Xcode", id: 497799835`, Line: 13},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Parse =\n%#v\nwant\n%#v", entries, want)
+	}
+}
+
+func TestParseFileComments(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		header  []string
+		doc     []string // of the first entry
+		trailer []string
+	}{
+		{
+			name:    "header above the first entry",
+			content: "# My machine\nbrew \"jq\"\n",
+			header:  []string{"# My machine"},
+		},
+		{
+			name:    "header separated by a blank line",
+			content: "\n# My machine\n\n# needed by scripts\nbrew \"jq\"\n",
+			header:  []string{"# My machine"},
+			doc:     []string{"# needed by scripts"},
+		},
+		{
+			name:    "header ends at a section title",
+			content: "# My machine\n# Formulae (command-line tools)\n# needed by scripts\nbrew \"jq\"\n",
+			header:  []string{"# My machine"},
+			doc:     []string{"# Formulae (command-line tools)", "# needed by scripts"},
+		},
+		{
+			name:    "several header blocks",
+			content: "# My machine\n\n\n# Run brew bundle\n\n# needed by scripts\n\nbrew \"jq\"\n",
+			header:  []string{"# My machine", "", "# Run brew bundle", "", "# needed by scripts"},
+		},
+		{
+			name:    "trailing comments",
+			content: "brew \"jq\"\n# one\n\n# two\n\n",
+			trailer: []string{"# one", "", "# two", ""},
+		},
+		{
+			name:    "only comments",
+			content: "# one\n\n# two\n",
+			header:  []string{"# one", "", "# two"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := parseFile(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var doc []string
+			if len(f.entries) > 0 {
+				doc = f.entries[0].Doc
+			}
+			if !reflect.DeepEqual(f.header, tt.header) || !reflect.DeepEqual(doc, tt.doc) || !reflect.DeepEqual(f.trailer, tt.trailer) {
+				t.Errorf("parseFile = header %q, doc %q, trailer %q, want %q, %q, %q",
+					f.header, doc, f.trailer, tt.header, tt.doc, tt.trailer)
+			}
+		})
+	}
+}
+
+func TestSplitName(t *testing.T) {
+	tests := []struct {
+		s, name, options string
+	}{
+		{`"jq"`, "jq", ""},
+		{`'jq', args: ["HEAD"]`, "jq", `, args: ["HEAD"]`},
+		{`jq, restart_service: true`, "jq", `, restart_service: true`},
+		{`"unterminated`, "unterminated", ""},
+		{``, "", ""},
+	}
+	for _, tt := range tests {
+		name, options := splitName(tt.s)
+		if name != tt.name || options != tt.options {
+			t.Errorf("splitName(%q) = %q, %q, want %q, %q", tt.s, name, options, tt.name, tt.options)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package brewfile
+
+import (
+	"strings"
+)
+
+// generatedComment reports whether a comment line was written by
+// go-brew-search itself and should not survive reformatting
+func generatedComment(line string) bool {
+	switch line {
+	case "# Taps", "# Formulae (command-line tools)", "# Casks (GUI applications)", "# Other":
+		return true
+	}
+	return strings.HasPrefix(line, "# Added by go-brew-search") ||
+		strings.HasPrefix(line, "# Generated by go-brew-search")
+}
+
+// Format rewrites Brewfile content into sorted taps, formulae and casks
+// sections. Options and comments are kept: inline comments stay on their
+// line, comments above an entry move with it, and the header at the top of
+// the file and comments after the last entry stay where they are.
+func Format(content string) (string, error) {
+	f, err := parseFile(strings.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+
+	for i := range f.entries {
+		f.entries[i].Doc = userComments(f.entries[i].Doc)
+	}
+
+	var parts []string
+	if header := userComments(f.header); len(header) > 0 {
+		parts = append(parts, strings.Join(header, "\n")+"\n")
+	}
+	if len(f.entries) > 0 {
+		parts = append(parts, Render(f.entries))
+	}
+	if trailer := userComments(f.trailer); len(trailer) > 0 {
+		parts = append(parts, strings.Join(trailer, "\n")+"\n")
+	}
+
+	return strings.Join(parts, "\n"), nil
+}
+
+// userComments drops generated comments and the blank lines around them,
+// keeping single blank lines between the remaining comment blocks
+func userComments(lines []string) []string {
+	var result []string
+	for _, line := range lines {
+		if line == "" {
+			if len(result) > 0 && result[len(result)-1] != "" {
+				result = append(result, line)
+			}
+			continue
+		}
+		if !generatedComment(line) {
+			result = append(result, line)
+		}
+	}
+
+	for len(result) > 0 && result[len(result)-1] == "" {
+		result = result[:len(result)-1]
+	}
+	return result
+}
@@ -0,0 +1,160 @@
+package brewfile
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name: "sorts into sections",
+			content: `cask "firefox"
+brew "wget"
+mas "Xcode", id: 497799835
+tap "user/tools"
+brew "jq", args: ["HEAD"] # JSON
+`,
+			want: `# Taps
+tap "user/tools"
+
+# Formulae (command-line tools)
+brew "jq", args: ["HEAD"] # JSON
+brew "wget"
+
+# Casks (GUI applications)
+cask "firefox"
+
+# Other
+mas "Xcode", id: 497799835
+`,
+		},
+		{
+			name: "keeps the header and doc comments",
+			content: `# My machine
+# Run brew bundle after editing
+
+brew "wget"
+# needed by scripts
+brew "jq"
+`,
+			want: `# My machine
+# Run brew bundle after editing
+
+# Formulae (command-line tools)
+# needed by scripts
+brew "jq"
+brew "wget"
+`,
+		},
+		{
+			name: "drops generated comments",
+			content: `# Generated by go-brew-search
+
+# Formulae (command-line tools)
+brew "jq"
+# Added by go-brew-search on 2024-01-01
+brew "fd"
+`,
+			want: `# Formulae (command-line tools)
+brew "fd"
+brew "jq"
+`,
+		},
+		{
+			name: "keeps the header above section titles",
+			content: `# My machine
+tap "user/tools"
+brew "jq"
+`,
+			want: `# My machine
+
+# Taps
+tap "user/tools"
+
+# Formulae (command-line tools)
+brew "jq"
+`,
+		},
+		{
+			name: "keeps comments separated by blank lines",
+			content: `brew "wget"
+
+# --- dev tools, keep pinned! ---
+
+# search
+brew "ripgrep"
+brew "fd"
+`,
+			want: `# Formulae (command-line tools)
+brew "fd"
+# --- dev tools, keep pinned! ---
+
+# search
+brew "ripgrep"
+brew "wget"
+`,
+		},
+		{
+			name: "keeps trailing comments",
+			content: `cask "firefox"
+brew "jq"
+# trailing note
+
+# and another
+`,
+			want: `# Formulae (command-line tools)
+brew "jq"
+
+# Casks (GUI applications)
+cask "firefox"
+
+# trailing note
+
+# and another
+`,
+		},
+		{
+			name:    "only comments",
+			content: "# nothing yet\n",
+			want:    "# nothing yet\n",
+		},
+		{
+			name:    "empty",
+			content: "",
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format(tt.content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Format =\n%s\nwant\n%s", got, tt.want)
+			}
+
+			// No comment of the user's is lost
+			for _, line := range strings.Split(tt.content, "\n") {
+				line = strings.TrimSpace(line)
+				if strings.HasPrefix(line, "#") && !generatedComment(line) && !strings.Contains(got, line) {
+					t.Errorf("Format dropped %q", line)
+				}
+			}
+
+			// Formatting is stable
+			again, err := Format(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again != got {
+				t.Errorf("Format is not idempotent:\n%s\nthen\n%s", got, again)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package brewfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/user/go-brew-search/internal/api"
+)
+
+// Issue is a problem found in a Brewfile
+type Issue struct {
+	Entry   Entry
+	Message string
+}
+
+// Lint checks Brewfile entries against the package index: duplicates,
+// unknown or deprecated packages, aliases and old names, formulae declared
+// as casks and the other way round, and packages from taps that aren't
+// declared. Unknown packages come with suggestions for likely typos.
+// Packages from third-party taps can't be checked against the index.
+func Lint(entries []Entry, packages []api.Package) []Issue {
+	resolver := api.NewResolver(packages)
+
+	taps := make(map[string]bool)
+	for _, e := range entries {
+		if e.Kind == "tap" {
+			taps[NormalizeName("tap", e.Name)] = true
+		}
+	}
+
+	var issues []Issue
+	report := func(e Entry, format string, args ...any) {
+		issues = append(issues, Issue{Entry: e, Message: fmt.Sprintf(format, args...)})
+	}
+
+	seen := make(map[string]Entry)
+	for _, e := range entries {
+		if !e.IsPackage() {
+			continue
+		}
+
+		key := e.Kind + ":" + NormalizeName(e.Kind, e.Name)
+		if first, ok := seen[key]; ok {
+			report(e, "duplicate of line %d", first.Line)
+			continue
+		}
+		seen[key] = e
+
+		if e.Kind == "tap" {
+			continue
+		}
+
+		name := NormalizeName(e.Kind, e.Name)
+		if tap, ok := tapOf(name); ok {
+			if !taps[tap] && !defaultTaps[tap] {
+				report(e, "tap %q is not declared", tap)
+			}
+			continue
+		}
+
+		pkgType, otherType, otherKind := "formula", "cask", "cask"
+		if e.Kind == "cask" {
+			pkgType, otherType, otherKind = "cask", "formula", "brew"
+		}
+
+		res := resolver.Resolve(name, pkgType)
+		if res.Canonical() {
+			if res.Packages[0].Deprecated {
+				report(e, "%s is deprecated", e.Name)
+			}
+			continue
+		}
+
+		if other := resolver.Resolve(name, otherType); other.Canonical() {
+			report(e, "%s is a %s; use %s \"%s\"", e.Name, otherType, otherKind, e.Name)
+			continue
+		}
+
+		if len(res.Packages) > 0 {
+			token := res.Packages[0].Token
+			report(e, "%s is an %s of %s; use %s \"%s\"", e.Name, res.Match, token, e.Kind, token)
+			continue
+		}
+
+		if len(res.Suggestions) > 0 {
+			report(e, "unknown %s %s; did you mean %s?", pkgType, e.Name, res.Suggestions[0].Token)
+			continue
+		}
+		report(e, "unknown %s %s", pkgType, e.Name)
+	}
+
+	return issues
+}
+
+// tapOf returns the tap of a tap-qualified package name such as
+// "user/repo/package"
+func tapOf(name string) (string, bool) {
+	parts := strings.Split(name, "/")
+	if len(parts) != 3 {
+		return "", false
+	}
+	return NormalizeName("tap", parts[0]+"/"+parts[1]), true
+}
@@ -0,0 +1,65 @@
+package brewfile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/user/go-brew-search/internal/api"
+)
+
+func TestLint(t *testing.T) {
+	packages := []api.Package{
+		{Token: "jq", Type: "formula"},
+		{Token: "ripgrep", Type: "formula"},
+		{Token: "python@3.13", FullName: "python@3.13", Type: "formula", Aliases: []string{"python3"}},
+		{Token: "youtube-dl", Type: "formula", Deprecated: true},
+		{Token: "firefox", Type: "cask"},
+		{Token: "docker", Type: "cask"},
+	}
+
+	entries, err := Parse(strings.NewReader(`tap "user/homebrew-tools"
+brew "jq"
+brew "JQ"
+brew "python3"
+brew "youtube-dl"
+brew "firefox"
+cask "jq"
+brew "nosuchthing"
+brew "ripgerp"
+brew "user/tools/fd"
+brew "other/tap/thing"
+brew "homebrew/core/jq"
+cask "docker"
+mas "Xcode", id: 497799835
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, issue := range Lint(entries, packages) {
+		got = append(got, issue.Entry.Raw+": "+issue.Message)
+	}
+	want := []string{
+		`brew "JQ": duplicate of line 2`,
+		`brew "python3": python3 is an alias of python@3.13; use brew "python@3.13"`,
+		`brew "youtube-dl": youtube-dl is deprecated`,
+		`brew "firefox": firefox is a cask; use cask "firefox"`,
+		`cask "jq": jq is a formula; use brew "jq"`,
+		`brew "nosuchthing": unknown formula nosuchthing`,
+		`brew "ripgerp": unknown formula ripgerp; did you mean ripgrep?`,
+		`brew "other/tap/thing": tap "other/tap" is not declared`,
+		`brew "homebrew/core/jq": duplicate of line 2`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLintClean(t *testing.T) {
+	entries := []Entry{{Kind: "brew", Name: "jq", Line: 1}}
+	if issues := Lint(entries, []api.Package{{Token: "jq", Type: "formula"}}); len(issues) != 0 {
+		t.Errorf("Lint = %+v, want no issues", issues)
+	}
+}
@@ -1,147 +1,254 @@
 package brewfile
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/brew"
+	"github.com/user/go-brew-search/internal/diff"
 )
 
 type Manager struct {
-	path string
+	path   string
+	runner brew.Runner
+	dryRun io.Writer
 }
 
-func New(path string) *Manager {
+func New(path string, runner brew.Runner) *Manager {
 	return &Manager{
-		path: path,
+		path:   path,
+		runner: runner,
 	}
 }
 
-// LoadExisting loads existing packages from Brewfile
-func (m *Manager) LoadExisting() (map[string]bool, error) {
-	existing := make(map[string]bool)
+// Path returns the location of the Brewfile
+func (m *Manager) Path() string {
+	return m.path
+}
 
+// Entries parses the Brewfile. A missing Brewfile has no entries.
+func (m *Manager) Entries() ([]Entry, error) {
 	file, err := os.Open(m.path)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Brewfile doesn't exist yet, that's okay
-			return existing, nil
+			return nil, nil
 		}
 		return nil, err
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		// Parse brew formula
-		if strings.HasPrefix(line, "brew ") {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				pkg := strings.Trim(parts[1], `"'`)
-				existing[pkg] = true
-			}
-		}
+	return Parse(file)
+}
 
-		// Parse cask
-		if strings.HasPrefix(line, "cask ") {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				pkg := strings.Trim(parts[1], `"'`)
-				existing[pkg] = true
-			}
-		}
+// LoadExisting loads existing packages from Brewfile
+func (m *Manager) LoadExisting() (map[string]bool, error) {
+	entries, err := m.Entries()
+	if err != nil {
+		return nil, err
+	}
 
-		// Parse tap
-		if strings.HasPrefix(line, "tap ") {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				tap := strings.Trim(parts[1], `"'`)
-				existing[tap] = true
-			}
+	existing := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		if e.IsPackage() {
+			existing[e.Name] = true
 		}
 	}
 
-	return existing, scanner.Err()
+	return existing, nil
 }
 
 // AddPackages adds new packages to the Brewfile
 func (m *Manager) AddPackages(packages []api.Package) error {
-	// Ensure directory exists
-	dir := filepath.Dir(m.path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-
-	// Open file in append mode
-	file, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	old, err := m.read()
 	if err != nil {
 		return err
 	}
-	defer file.Close()
 
-	// Check if file is empty or needs a newline
-	stat, err := file.Stat()
-	if err != nil {
-		return err
-	}
+	var b strings.Builder
+	b.WriteString(old)
 
-	if stat.Size() > 0 {
+	if len(old) > 0 {
 		// Add newline if file doesn't end with one
-		buf := make([]byte, 1)
-		if _, err := file.ReadAt(buf, stat.Size()-1); err == nil && buf[0] != '\n' {
-			file.WriteString("\n")
+		if !strings.HasSuffix(old, "\n") {
+			b.WriteString("\n")
 		}
-		file.WriteString("\n")
+		b.WriteString("\n")
 	}
 
 	// Add comment header
-	file.WriteString(fmt.Sprintf("# Added by go-brew-search on %s\n", 
+	b.WriteString(fmt.Sprintf("# Added by go-brew-search on %s\n",
 		time.Now().Format("2006-01-02 15:04:05")))
 
 	// Add packages
 	for _, pkg := range packages {
-		var line string
-		if pkg.Type == "cask" {
-			line = fmt.Sprintf("cask \"%s\"", pkg.Token)
-		} else {
-			line = fmt.Sprintf("brew \"%s\"", pkg.Token)
+		b.WriteString(EntryFor(pkg).String() + "\n")
+	}
+
+	return m.write(old, b.String())
+}
+
+// WriteEntries replaces the Brewfile with the given entries
+func (m *Manager) WriteEntries(entries []Entry) error {
+	old, err := m.read()
+	if err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf("# Generated by go-brew-search on %s\n\n",
+		time.Now().Format("2006-01-02 15:04:05"))
+	content += Render(entries)
+
+	return m.write(old, content)
+}
+
+// Format sorts and groups the Brewfile in place
+func (m *Manager) Format() error {
+	old, err := m.read()
+	if err != nil {
+		return err
+	}
+
+	content, err := Format(old)
+	if err != nil {
+		return err
+	}
+
+	if content == old {
+		return nil
+	}
+	return m.write(old, content)
+}
+
+// IsFormatted reports whether Format would leave the Brewfile unchanged
+func (m *Manager) IsFormatted() (bool, error) {
+	old, err := m.read()
+	if err != nil {
+		return false, err
+	}
+
+	content, err := Format(old)
+	if err != nil {
+		return false, err
+	}
+	return content == old, nil
+}
+
+// RemovePackages removes brew, cask and tap entries with the given names
+// and returns the entries that were removed
+func (m *Manager) RemovePackages(names []string) ([]Entry, error) {
+	old, err := m.read()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := Parse(strings.NewReader(old))
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[strings.ToLower(name)] = true
+	}
+
+	var removed []Entry
+	drop := make(map[int]bool)
+	for _, e := range entries {
+		if e.IsPackage() && (wanted[strings.ToLower(e.Name)] || wanted[NormalizeName(e.Kind, e.Name)]) {
+			removed = append(removed, e)
+			drop[e.Line] = true
 		}
+	}
+
+	if len(removed) == 0 {
+		return nil, nil
+	}
 
-		if pkg.Description != "" {
-			// Add comment with description
-			desc := pkg.Description
-			if len(desc) > 60 {
-				desc = desc[:60] + "..."
-			}
-			line += fmt.Sprintf(" # %s", desc)
+	var kept []string
+	for i, line := range strings.SplitAfter(old, "\n") {
+		if !drop[i+1] {
+			kept = append(kept, line)
 		}
+	}
+
+	return removed, m.write(old, strings.Join(kept, ""))
+}
 
-		if _, err := file.WriteString(line + "\n"); err != nil {
-			return err
+// SetDryRun makes the manager print a unified diff of every change to out
+// instead of writing the Brewfile. A nil out turns dry-run off.
+func (m *Manager) SetDryRun(out io.Writer) {
+	m.dryRun = out
+}
+
+// read returns the current Brewfile contents; a missing file is empty
+func (m *Manager) read() (string, error) {
+	data, err := os.ReadFile(m.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
 		}
+		return "", err
+	}
+	return string(data), nil
+}
+
+// write replaces the Brewfile contents, or shows the diff in dry-run mode.
+// The new contents go to a temporary file that is renamed over the
+// Brewfile, so an interrupted write never leaves it empty or half-written.
+// A symlinked Brewfile, as kept in a dotfiles repository, stays a symlink.
+func (m *Manager) write(old, content string) error {
+	if m.dryRun != nil {
+		fmt.Fprint(m.dryRun, diff.Unified(m.path, m.path, old, content))
+		return nil
+	}
+
+	path := m.path
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+
+	// Ensure directory exists
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
 	}
 
-	return nil
+	return os.Rename(tmp.Name(), path)
 }
 
 // RunBundle runs brew bundle command
 func (m *Manager) RunBundle() error {
-	cmd := exec.Command("brew", "bundle", "--file", m.path)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Stdin = os.Stdin
-	
-	return cmd.Run()
-}
\ No newline at end of file
+	return m.runner.Run("bundle", "--file", m.path)
+}
@@ -0,0 +1,136 @@
+package brewfile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/brew"
+)
+
+func TestRunBundle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Brewfile")
+	fake := brew.NewFakeRunner()
+
+	if err := New(path, fake).RunBundle(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []brew.Call{{Args: []string{"bundle", "--file", path}, Interactive: true}}
+	if calls := fake.Calls(); !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %+v, want %+v", calls, want)
+	}
+}
+
+func TestRunBundleError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Brewfile")
+	fake := brew.NewFakeRunner().On("bundle --file "+path, brew.Response{ExitCode: 1, Stderr: "Error: jq failed"})
+
+	err := New(path, fake).RunBundle()
+	if err == nil {
+		t.Fatal("RunBundle succeeded, want brew's failure")
+	}
+	if want := "brew bundle --file " + path + ": exit status 1: Error: jq failed"; err.Error() != want {
+		t.Errorf("err = %q, want %q", err, want)
+	}
+}
+
+func TestAddPackagesWritesInPlace(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Brewfile")
+	if err := os.WriteFile(path, []byte("# My machine\nbrew \"jq\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := New(path, brew.NewFakeRunner())
+	if err := m.AddPackages([]api.Package{{Token: "wget", Type: "formula"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if !strings.HasPrefix(content, "# My machine\nbrew \"jq\"\n\n# Added by go-brew-search on ") || !strings.HasSuffix(content, "\nbrew \"wget\"\n") {
+		t.Errorf("Brewfile =\n%s", content)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want the original 0600", info.Mode().Perm())
+	}
+
+	// The temporary file was renamed into place
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Errorf("directory holds %d files, want only the Brewfile", len(files))
+	}
+}
+
+func TestWriteFollowsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "Brewfile")
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("brew \"wget\"\nbrew \"jq\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "Brewfile")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+
+	if err := New(link, brew.NewFakeRunner()).Format(); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Brewfile is no longer a symlink: %v, %v", info, err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "# Formulae (command-line tools)\nbrew \"jq\"\nbrew \"wget\"\n" {
+		t.Errorf("target =\n%s", data)
+	}
+}
+
+func TestWriteCreatesBrewfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "new", "Brewfile")
+	if err := New(path, brew.NewFakeRunner()).WriteEntries([]Entry{{Kind: "brew", Name: "jq"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("mode = %v, want 0644", info.Mode().Perm())
+	}
+}
+
+func TestDryRunLeavesBrewfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Brewfile")
+	if err := os.WriteFile(path, []byte("brew \"jq\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	m := New(path, brew.NewFakeRunner())
+	m.SetDryRun(&out)
+	if err := m.AddPackages([]api.Package{{Token: "wget", Type: "formula"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != "brew \"jq\"\n" {
+		t.Errorf("Brewfile = %q, want it untouched", data)
+	}
+	if diff := out.String(); !strings.HasPrefix(diff, "--- "+path) || !strings.Contains(diff, "\n+brew \"wget\"\n") {
+		t.Errorf("dry run printed\n%s", diff)
+	}
+}
@@ -0,0 +1,86 @@
+package brewfile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/text"
+)
+
+// maxCommentLen is the width of the longest description written as a
+// Brewfile comment
+const maxCommentLen = 60
+
+// EntryFor returns the Brewfile entry for a package, with its description
+// as the comment.
+func EntryFor(pkg api.Package) Entry {
+	e := Entry{Kind: "brew", Name: pkg.Token}
+	if pkg.Type == "cask" {
+		e.Kind = "cask"
+	}
+
+	if pkg.Description != "" {
+		e.Comment = text.Truncate(pkg.Description, maxCommentLen)
+	}
+
+	return e
+}
+
+// String formats the entry as a Brewfile line
+func (e Entry) String() string {
+	if !e.IsPackage() {
+		return e.Raw
+	}
+
+	line := fmt.Sprintf("%s \"%s\"", e.Kind, e.Name)
+	if e.Options != "" {
+		line += e.Options
+	}
+	if e.Comment != "" {
+		line += fmt.Sprintf(" # %s", e.Comment)
+	}
+	return line
+}
+
+// Render formats entries as a Brewfile grouped into taps, formulae and casks,
+// each section sorted by name.
+func Render(entries []Entry) string {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sortEntries(sorted)
+
+	sections := []struct {
+		title string
+		match func(e Entry) bool
+	}{
+		{"# Taps", func(e Entry) bool { return e.Kind == "tap" }},
+		{"# Formulae (command-line tools)", func(e Entry) bool { return e.Kind == "brew" }},
+		{"# Casks (GUI applications)", func(e Entry) bool { return e.Kind == "cask" || e.Kind == "cask_args" }},
+		{"# Other", func(e Entry) bool { return !e.IsPackage() && e.Kind != "cask_args" }},
+	}
+
+	var b strings.Builder
+	for _, section := range sections {
+		var lines []string
+		for _, e := range sorted {
+			if section.match(e) {
+				lines = append(lines, e.Doc...)
+				lines = append(lines, e.String())
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(section.title + "\n")
+		for _, line := range lines {
+			b.WriteString(line + "\n")
+		}
+	}
+
+	return b.String()
+}
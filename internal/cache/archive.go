@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxArchiveEntry bounds the size of a single imported entry
+const maxArchiveEntry = 512 << 20
+
+// Export writes the newest copy of every cached entry to w as a gzipped tar
+// archive, keeping the time each entry was stored
+func (s *FSStore) Export(w io.Writer) ([]Info, error) {
+	infos, err := s.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cache entries: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for _, info := range infos {
+		data, err := os.ReadFile(info.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", info.Key, err)
+		}
+
+		header := &tar.Header{
+			Name:    info.Key + ext,
+			Mode:    0644,
+			Size:    int64(len(data)),
+			ModTime: info.Stored,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// Import reads an archive written by Export and stores its entries in the
+// cache's own directory, replacing existing ones. The archive is checked in
+// full before anything is written.
+func (s *FSStore) Import(r io.Reader) ([]string, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a cache archive: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	var keys []string
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		key, err := archiveKey(header.Name)
+		if err != nil {
+			return nil, err
+		}
+		if header.Size > maxArchiveEntry {
+			return nil, fmt.Errorf("archive entry %s is too large", header.Name)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from archive: %w", header.Name, err)
+		}
+
+		e, err := parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("archive entry %s is not a cache entry: %w", header.Name, err)
+		}
+		if e.Schema != s.schema {
+			return nil, fmt.Errorf("archive entry %s was written by an incompatible version", header.Name)
+		}
+
+		if _, ok := files[key]; !ok {
+			keys = append(keys, key)
+		}
+		files[key] = data
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("archive contains no cache entries")
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if err := s.write(key, files[key]); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", key, err)
+		}
+	}
+	return keys, nil
+}
+
+// archiveKey returns the cache key for an archive member, such as
+// "detail/formula/jq" for "detail/formula/jq.bin". Names that could point
+// outside the cache directory, such as absolute ones or ones with "..", are
+// rejected, as is anything that isn't a .bin file.
+func archiveKey(name string) (string, error) {
+	clean := path.Clean(name)
+	if strings.Contains(name, `\`) || path.IsAbs(clean) || !filepath.IsLocal(filepath.FromSlash(clean)) ||
+		path.Ext(clean) != ext {
+		return "", fmt.Errorf("unexpected file %q in archive", name)
+	}
+	for i, elem := range strings.Split(clean, "/") {
+		if strings.HasPrefix(elem, ".") || i == 0 && elem == quarantineDir {
+			return "", fmt.Errorf("unexpected file %q in archive", name)
+		}
+	}
+	return strings.TrimSuffix(clean, ext), nil
+}
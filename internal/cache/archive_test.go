@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := NewFS(t.TempDir())
+	entries := map[string]string{
+		"formulae":                    "formula list",
+		"detail/formula/jq":           "jq details",
+		"history/formulae/2024-01-01": "snapshot",
+	}
+	for key, value := range entries {
+		if err := src.Set(key, value, [32]byte{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := src.Export(&buf); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	dst := NewFS(t.TempDir())
+	keys, err := dst.Import(&buf)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if len(keys) != len(entries) {
+		t.Errorf("imported %v, want %d entries", keys, len(entries))
+	}
+	for key, want := range entries {
+		var got string
+		if _, err := dst.Get(key, &got); err != nil {
+			t.Errorf("Get(%q): %v", key, err)
+		} else if got != want {
+			t.Errorf("Get(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestArchiveKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "formulae.bin", want: "formulae"},
+		{name: "detail/formula/jq.bin", want: "detail/formula/jq"},
+		{name: "./detail//cask/firefox.bin", want: "detail/cask/firefox"},
+		{name: "../formulae.bin", wantErr: true},
+		{name: "detail/../../formulae.bin", wantErr: true},
+		{name: "/etc/formulae.bin", wantErr: true},
+		{name: `detail\..\formulae.bin`, wantErr: true},
+		{name: ".hidden.bin", wantErr: true},
+		{name: "detail/.git/x.bin", wantErr: true},
+		{name: "quarantine/formulae.bin", wantErr: true},
+		{name: "formulae.json", wantErr: true},
+		{name: "detail/formula/jq.bin.lock", wantErr: true},
+		{name: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := archiveKey(tt.name)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("archiveKey(%q) = %q, want an error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("archiveKey(%q): %v", tt.name, err)
+		} else if got != tt.want {
+			t.Errorf("archiveKey(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
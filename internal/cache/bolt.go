@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltBucket holds every entry in a BoltStore
+var boltBucket = []byte("entries")
+
+// BoltStore keeps entries in a single bbolt database file, in the same
+// encoding as FSStore. bbolt allows one process at a time to open the file.
+// Reads don't write to the database, so Info.Accessed is the fetch time.
+type BoltStore struct {
+	db     *bolt.DB
+	schema uint32
+}
+
+// NewBolt opens or creates the database at path, waiting up to a few
+// seconds for another process to release it
+func NewBolt(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache database %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// SetSchema sets the version of the data the caller stores, as for FSStore
+func (s *BoltStore) SetSchema(schema uint32) {
+	s.schema = schema
+}
+
+// Close releases the database file
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *BoltStore) Get(key string, dest any) (Info, error) {
+	var e *entry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(boltBucket).Get([]byte(key))
+		if data == nil {
+			return fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+
+		// data is only valid inside the transaction
+		var err error
+		e, err = s.parse(key, bytes.Clone(data))
+		return err
+	})
+	if err != nil {
+		return Info{}, err
+	}
+
+	return s.info(key, e), e.decode(dest)
+}
+
+func (s *BoltStore) Set(key string, data any, source [32]byte) error {
+	h := header{
+		Schema: s.schema,
+		Stored: time.Now(),
+		Source: source,
+	}
+
+	var buf bytes.Buffer
+	if err := encode(&buf, h, data); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Put([]byte(key), buf.Bytes())
+	})
+}
+
+func (s *BoltStore) Delete(key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Delete([]byte(key))
+	})
+}
+
+func (s *BoltStore) Stat(key string) (Info, error) {
+	var info Info
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(boltBucket).Get([]byte(key))
+		if data == nil {
+			return fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+
+		var err error
+		info, err = s.stat(key, data)
+		return err
+	})
+	return info, err
+}
+
+func (s *BoltStore) List() ([]Info, error) {
+	var infos []Info
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).ForEach(func(k, v []byte) error {
+			if info, err := s.stat(string(k), v); err == nil {
+				infos = append(infos, info)
+			}
+			return nil
+		})
+	})
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Key < infos[j].Key
+	})
+	return infos, err
+}
+
+// stat describes a stored value from its header
+func (s *BoltStore) stat(key string, data []byte) (Info, error) {
+	h, err := parseHeader(data)
+	if err != nil {
+		return Info{}, fmt.Errorf("%s: %w", key, err)
+	}
+	if h.Format != formatVersion || h.Schema != s.schema {
+		return Info{}, fmt.Errorf("%w: %s: %w", ErrNotFound, key, errVersion)
+	}
+
+	return Info{
+		Key:      key,
+		Size:     int64(len(data)),
+		Stored:   h.Stored,
+		Accessed: h.Stored,
+		Source:   h.Source,
+	}, nil
+}
+
+// parse verifies a stored value and checks its schema
+func (s *BoltStore) parse(key string, data []byte) (*entry, error) {
+	e, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	if e.Schema != s.schema {
+		return nil, fmt.Errorf("%w: %s: %w", ErrNotFound, key, errVersion)
+	}
+	return e, nil
+}
+
+func (s *BoltStore) info(key string, e *entry) Info {
+	return Info{
+		Key:      key,
+		Size:     int64(headerSize + len(e.payload)),
+		Stored:   e.Stored,
+		Accessed: e.Stored,
+		Source:   e.Source,
+	}
+}
@@ -0,0 +1,501 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// quarantineDir holds entries that could not be decoded, for inspection
+const quarantineDir = "quarantine"
+
+var (
+	// errCorrupt marks an entry that exists but cannot be decoded
+	errCorrupt = errors.New("corrupt cache entry")
+	// errVersion marks an entry written in another format or schema. It is
+	// treated as a miss and replaced on the next write.
+	errVersion = errors.New("cache entry version mismatch")
+)
+
+// FSStore keeps one file per entry in a directory. Writes are atomic and
+// locked per key, so several processes can share the directory.
+type FSStore struct {
+	dir    string
+	shared string
+	schema uint32
+}
+
+// NewFS returns a store writing to dir
+func NewFS(dir string) *FSStore {
+	return &FSStore{dir: dir}
+}
+
+// SetShared adds a read-only cache directory, such as one pre-populated on a
+// shared host. When both directories hold an entry, the newer one is used;
+// nothing is ever written to or removed from the shared directory.
+func (s *FSStore) SetShared(dir string) {
+	s.shared = dir
+}
+
+// SetSchema sets the version of the data the caller stores. Entries written
+// with another schema are treated as misses, so changing the stored types
+// only needs a new version number.
+func (s *FSStore) SetSchema(schema uint32) {
+	s.schema = schema
+}
+
+// Dir returns the directory the store writes to
+func (s *FSStore) Dir() string {
+	return s.dir
+}
+
+// Get decodes the newer of the own and shared entries for key
+func (s *FSStore) Get(key string, dest any) (Info, error) {
+	e, err := s.readOwn(key)
+	own := err == nil
+
+	if s.shared != "" {
+		shared, sharedErr := s.read(key, s.sharedPath(key))
+		if sharedErr == nil && (err != nil || shared.Stored.After(e.Stored)) {
+			e, err, own = shared, nil, false
+		}
+	}
+
+	if err != nil {
+		return Info{}, err
+	}
+
+	info := Info{
+		Key:      key,
+		Size:     int64(headerSize + len(e.payload)),
+		Stored:   e.Stored,
+		Accessed: time.Now(),
+		Source:   e.Source,
+		Shared:   !own,
+		Path:     s.cachePath(key),
+	}
+	if !own {
+		info.Path = s.sharedPath(key)
+		return info, e.decode(dest)
+	}
+
+	// The modification time records the last access for LRU eviction; the
+	// fetch time is in the header
+	os.Chtimes(info.Path, time.Time{}, info.Accessed)
+
+	return info, s.decodeOwn(key, e, dest)
+}
+
+// Set replaces the entry for key in the own directory
+func (s *FSStore) Set(key string, data any, source [32]byte) error {
+	h := header{
+		Schema: s.schema,
+		Stored: time.Now(),
+		Source: source,
+	}
+
+	var buf bytes.Buffer
+	if err := encode(&buf, h, data); err != nil {
+		return err
+	}
+
+	return s.write(key, buf.Bytes())
+}
+
+// Delete removes the entry for key from the own directory
+func (s *FSStore) Delete(key string) error {
+	unlock, err := lockFile(s.lockPath(key))
+	if err != nil {
+		return fmt.Errorf("failed to lock cache entry %s: %w", key, err)
+	}
+	defer unlock()
+
+	if err := os.Remove(s.cachePath(key)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Stat describes the entry Get would return, reading only headers
+func (s *FSStore) Stat(key string) (Info, error) {
+	info, err := s.stat(key, s.cachePath(key), false)
+
+	if s.shared != "" {
+		shared, sharedErr := s.stat(key, s.sharedPath(key), true)
+		if sharedErr == nil && (err != nil || shared.Stored.After(info.Stored)) {
+			return shared, nil
+		}
+	}
+
+	return info, err
+}
+
+// List describes the entry Get would return for every key
+func (s *FSStore) List() ([]Info, error) {
+	infos, err := s.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	newest := make(map[string]Info)
+	var keys []string
+	for _, info := range infos {
+		current, ok := newest[info.Key]
+		if !ok {
+			keys = append(keys, info.Key)
+		}
+		if !ok || info.Stored.After(current.Stored) {
+			newest[info.Key] = info
+		}
+	}
+
+	sort.Strings(keys)
+	result := make([]Info, len(keys))
+	for i, key := range keys {
+		result[i] = newest[key]
+	}
+	return result, nil
+}
+
+// Entries lists the entries in the own directory, followed by those in the
+// shared directory, each sorted by key. Unreadable entries are skipped.
+// Entries are found in subdirectories too, for keys containing "/".
+func (s *FSStore) Entries() ([]Info, error) {
+	infos, err := s.entries(s.dir, false)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if s.shared != "" {
+		shared, err := s.entries(s.shared, true)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		infos = append(infos, shared...)
+	}
+
+	return infos, nil
+}
+
+func (s *FSStore) entries(dir string, shared bool) ([]Info, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, err
+	}
+
+	var infos []Info
+	err := s.walk(dir, func(path, key string) {
+		if filepath.Ext(path) != ext {
+			return
+		}
+		if info, err := s.stat(strings.TrimSuffix(key, ext), path, shared); err == nil {
+			infos = append(infos, info)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Key < infos[j].Key
+	})
+	return infos, nil
+}
+
+// stat describes the entry at path from its header
+func (s *FSStore) stat(key, path string, shared bool) (Info, error) {
+	h, err := readHeader(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Info{}, fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return Info{}, err
+	}
+	if h.Format != formatVersion || h.Schema != s.schema {
+		return Info{}, fmt.Errorf("%w: %s: %w", ErrNotFound, key, errVersion)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return Info{}, err
+	}
+
+	return Info{
+		Key:      key,
+		Size:     fi.Size(),
+		Stored:   h.Stored,
+		Accessed: fi.ModTime(),
+		Source:   h.Source,
+		Shared:   shared,
+		Path:     path,
+	}, nil
+}
+
+// walk calls fn for every file under dir except quarantined ones, with its
+// path relative to dir using "/" separators
+func (s *FSStore) walk(dir string, fn func(path, rel string)) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == quarantineDir && filepath.Dir(path) == filepath.Clean(dir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		fn(path, filepath.ToSlash(rel))
+		return nil
+	})
+}
+
+// Quarantined lists the corrupt entries that were moved aside
+func (s *FSStore) Quarantined() ([]string, error) {
+	files, err := os.ReadDir(filepath.Join(s.dir, quarantineDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var paths []string
+	for _, file := range files {
+		paths = append(paths, filepath.Join(s.dir, quarantineDir, file.Name()))
+	}
+	return paths, nil
+}
+
+// read loads the entry stored at path
+func (s *FSStore) read(key, path string) (*entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+		}
+		return nil, err
+	}
+
+	e, err := parse(data)
+	if err != nil {
+		if errors.Is(err, errVersion) {
+			return nil, fmt.Errorf("%w: %s: %w", ErrNotFound, key, err)
+		}
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	if e.Schema != s.schema {
+		return nil, fmt.Errorf("%w: %s: %w: schema %d", ErrNotFound, key, errVersion, e.Schema)
+	}
+	return e, nil
+}
+
+// readOwn reads an entry from the own directory. A corrupt entry, such as
+// one left half-written by a crash, is moved to the quarantine directory so
+// it is fetched again instead of failing on every run.
+func (s *FSStore) readOwn(key string) (*entry, error) {
+	e, err := s.read(key, s.cachePath(key))
+	if errors.Is(err, errCorrupt) {
+		s.quarantine(key, time.Time{})
+	}
+	return e, err
+}
+
+// decodeOwn decodes an entry read by readOwn, quarantining it if its
+// payload turns out to be corrupt
+func (s *FSStore) decodeOwn(key string, e *entry, dest any) error {
+	err := e.decode(dest)
+	if errors.Is(err, errCorrupt) {
+		s.quarantine(key, e.Stored)
+	}
+	return err
+}
+
+// quarantine moves a corrupt entry out of the way under the key's lock.
+// stored is when the corrupt entry was written, or zero if its header
+// could not be read; an entry rewritten by another process in the meantime
+// is left alone.
+func (s *FSStore) quarantine(key string, stored time.Time) {
+	unlock, err := lockFile(s.lockPath(key))
+	if err != nil {
+		return
+	}
+	defer unlock()
+
+	path := s.cachePath(key)
+	if e, err := s.read(key, path); err == nil {
+		if !e.Stored.Equal(stored) {
+			return
+		}
+	} else if !errors.Is(err, errCorrupt) {
+		return
+	}
+
+	dir := filepath.Join(s.dir, quarantineDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		os.Remove(path)
+		return
+	}
+
+	name := fmt.Sprintf("%s-%s%s", strings.ReplaceAll(key, "/", "_"), time.Now().Format("20060102T150405"), ext)
+	if err := os.Rename(path, filepath.Join(dir, name)); err != nil {
+		os.Remove(path)
+	}
+}
+
+// write replaces the entry for key atomically: the data goes to a temporary
+// file that is renamed into place, under a per-key lock shared with other
+// brew-search processes. Readers see either the old or the new entry, never
+// a partial one.
+func (s *FSStore) write(key string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(s.cachePath(key)), 0755); err != nil {
+		return err
+	}
+
+	unlock, err := lockFile(s.lockPath(key))
+	if err != nil {
+		return fmt.Errorf("failed to lock cache entry %s: %w", key, err)
+	}
+	defer unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.cachePath(key)), filepath.Base(key)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), s.cachePath(key)); err != nil {
+		return err
+	}
+
+	// Drop the JSON entry written by earlier versions
+	os.Remove(filepath.Join(s.dir, key+".json"))
+	return nil
+}
+
+// Clear removes every file the store has written to its own directory,
+// whatever its format, and the quarantine. Lock files are kept, since
+// another process may hold them, and files the store didn't write are left
+// alone.
+func (s *FSStore) Clear() error {
+	var files []string
+	err := s.walk(s.dir, func(path, rel string) {
+		if filepath.Ext(path) == ext || isTempFile(rel) || legacyFiles[rel] {
+			files = append(files, path)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, path := range files {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return os.RemoveAll(filepath.Join(s.dir, quarantineDir))
+}
+
+// RemoveOrphans deletes files in the own directory that no entry uses:
+// temporary files from interrupted writes, entries in an old format or
+// schema and legacy JSON entries. Only files untouched for olderThan are
+// removed, so writes in progress are left alone. Lock files are never
+// removed: another process may already have opened one and be waiting for
+// it, and deleting it would let a third process lock a new file at the same
+// path at the same time.
+func (s *FSStore) RemoveOrphans(olderThan time.Duration) ([]string, error) {
+	var orphans []string
+	err := s.walk(s.dir, func(path, rel string) {
+		info, err := os.Stat(path)
+		if err != nil || time.Since(info.ModTime()) < olderThan {
+			return
+		}
+
+		switch {
+		case isTempFile(rel), legacyFiles[rel]:
+		case filepath.Ext(path) == ext:
+			key := strings.TrimSuffix(rel, ext)
+			if _, err := s.stat(key, path, false); !errors.Is(err, errVersion) {
+				return
+			}
+		default:
+			return
+		}
+		orphans = append(orphans, path)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	for _, path := range orphans {
+		if os.Remove(path) == nil {
+			removed = append(removed, path)
+		}
+	}
+	return removed, nil
+}
+
+// legacyFiles are the JSON entries written by versions before the binary
+// format, by path relative to the cache directory
+var legacyFiles = map[string]bool{
+	"formulae.json": true,
+	"casks.json":    true,
+}
+
+// isTempFile reports whether rel, relative to the cache directory, is named
+// like the temporary files write creates: the key's base name, a random
+// number and ".tmp"
+func isTempFile(rel string) bool {
+	name, ok := strings.CutSuffix(path.Base(rel), ".tmp")
+	if !ok {
+		return false
+	}
+	i := strings.LastIndex(name, ".")
+	if i <= 0 || i == len(name)-1 {
+		return false
+	}
+	for _, r := range name[i+1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *FSStore) cachePath(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key)+ext)
+}
+
+func (s *FSStore) lockPath(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key)+".lock")
+}
+
+func (s *FSStore) sharedPath(key string) string {
+	return filepath.Join(s.shared, filepath.FromSlash(key)+ext)
+}
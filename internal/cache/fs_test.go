@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeFile creates a file under dir with a modification time age ago
+func writeFile(t *testing.T, dir, rel string, age time.Duration) string {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-age)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestClearRemovesOnlyOwnFiles(t *testing.T) {
+	dir := t.TempDir()
+	s := NewFS(dir)
+	if err := s.Set("formulae", []string{"jq"}, [32]byte{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("detail/formula/jq", "details", [32]byte{}); err != nil {
+		t.Fatal(err)
+	}
+
+	owned := []string{
+		writeFile(t, dir, "casks.json", 0),
+		writeFile(t, dir, "formulae.123456.tmp", 0),
+		writeFile(t, dir, "detail/formula/git.987.tmp", 0),
+		writeFile(t, dir, "quarantine/formulae-20240101T000000.bin", 0),
+	}
+	foreign := []string{
+		writeFile(t, dir, "README", 0),
+		writeFile(t, dir, "notes/sub/important.txt", 0),
+		writeFile(t, dir, "notes/settings.json", 0),
+		writeFile(t, dir, "draft.tmp", 0),
+	}
+
+	if err := s.Clear(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"formulae", "detail/formula/jq"} {
+		if exists(s.cachePath(key)) {
+			t.Errorf("entry %s survived Clear", key)
+		}
+	}
+	for _, path := range owned {
+		if exists(path) {
+			t.Errorf("%s survived Clear", path)
+		}
+	}
+	for _, path := range foreign {
+		if !exists(path) {
+			t.Errorf("Clear removed %s, which the store didn't write", path)
+		}
+	}
+}
+
+func TestRemoveOrphansRemovesOnlyOwnFiles(t *testing.T) {
+	dir := t.TempDir()
+	s := NewFS(dir)
+	if err := s.Set("formulae", []string{"jq"}, [32]byte{}); err != nil {
+		t.Fatal(err)
+	}
+
+	orphans := []string{
+		writeFile(t, dir, "formulae.json", 2*time.Hour),
+		writeFile(t, dir, "casks.4242.tmp", 2*time.Hour),
+	}
+	kept := []string{
+		s.cachePath("formulae"),
+		writeFile(t, dir, "detail/cask/firefox.lock", 2*time.Hour), // another process may be waiting on it
+		writeFile(t, dir, "formulae.77.tmp", 0),                    // a write in progress
+		writeFile(t, dir, "notes/settings.json", 2*time.Hour),
+		writeFile(t, dir, "notes/draft.tmp", 2*time.Hour),
+		writeFile(t, dir, "export.json", 2*time.Hour),
+	}
+
+	removed, err := s.RemoveOrphans(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != len(orphans) {
+		t.Errorf("removed %v, want %v", removed, orphans)
+	}
+	for _, path := range orphans {
+		if exists(path) {
+			t.Errorf("orphan %s survived", path)
+		}
+	}
+	for _, path := range kept {
+		if !exists(path) {
+			t.Errorf("RemoveOrphans removed %s", path)
+		}
+	}
+}
+
+func TestIsTempFile(t *testing.T) {
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{"formulae.123.tmp", true},
+		{"detail/formula/jq.4567.tmp", true},
+		{"draft.tmp", false},
+		{"formulae.abc.tmp", false},
+		{".123.tmp", false},
+		{"formulae.123.tmp.bak", false},
+	}
+	for _, tt := range tests {
+		if got := isTempFile(tt.rel); got != tt.want {
+			t.Errorf("isTempFile(%q) = %v, want %v", tt.rel, got, tt.want)
+		}
+	}
+}
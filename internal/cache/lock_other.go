@@ -0,0 +1,39 @@
+//go:build !unix
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// staleLock is how old a lock file must be before it is assumed to be left
+// over from a crashed process
+const staleLock = time.Minute
+
+// lockFile creates path exclusively, waiting while another process holds
+// it
+func lockFile(path string) (func(), error) {
+	deadline := time.Now().Add(2 * staleLock)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock %s", path)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
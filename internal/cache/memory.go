@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps entries in memory, for tests and for programs that
+// embed the API client without persisting anything. Values are stored
+// gob-encoded, so callers never share data with the store.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	info Info
+	data []byte
+}
+
+// NewMemory returns an empty in-memory store
+func NewMemory() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry)}
+}
+
+func (s *MemoryStore) Get(key string, dest any) (Info, error) {
+	s.mu.Lock()
+	e, ok := s.entries[key]
+	if ok {
+		e.info.Accessed = time.Now()
+		s.entries[key] = e
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return Info{}, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return e.info, gob.NewDecoder(bytes.NewReader(e.data)).Decode(dest)
+}
+
+func (s *MemoryStore) Set(key string, data any, source [32]byte) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(data); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[key] = memoryEntry{
+		info: Info{
+			Key:      key,
+			Size:     int64(buf.Len()),
+			Stored:   time.Now(),
+			Accessed: time.Now(),
+			Source:   source,
+		},
+		data: buf.Bytes(),
+	}
+	return nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+func (s *MemoryStore) Stat(key string) (Info, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return Info{}, fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	return e.info, nil
+}
+
+func (s *MemoryStore) List() ([]Info, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	infos := make([]Info, 0, len(s.entries))
+	for _, e := range s.entries {
+		infos = append(infos, e.info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Key < infos[j].Key
+	})
+	return infos, nil
+}
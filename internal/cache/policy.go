@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Policy decides how long entries stay fresh and which entries may be
+// pruned. Keys are matched exactly, or by prefix for patterns ending in "/",
+// with the longest match winning. A TTL of 0 never expires.
+type Policy struct {
+	TTL       time.Duration            // for keys without a more specific TTL
+	TTLs      map[string]time.Duration // by key or "prefix/"
+	MaxSize   int64                    // total bytes of evictable entries; 0 for no limit
+	Evictable []string                 // keys or "prefix/" patterns that may be pruned
+}
+
+// TTLFor returns how long the entry for key stays fresh
+func (p Policy) TTLFor(key string) time.Duration {
+	best := -1
+	ttl := p.TTL
+	for pattern, d := range p.TTLs {
+		if matches(pattern, key) && len(pattern) > best {
+			best, ttl = len(pattern), d
+		}
+	}
+	return ttl
+}
+
+// Fresh reports whether an entry stored at stored is within its TTL
+func (p Policy) Fresh(key string, stored time.Time) bool {
+	ttl := p.TTLFor(key)
+	return ttl == 0 || time.Since(stored) <= ttl
+}
+
+// IsEvictable reports whether the entry for key may be removed when it
+// expires or the cache grows past MaxSize. Bulk indexes are kept so offline
+// mode has something to fall back on.
+func (p Policy) IsEvictable(key string) bool {
+	for _, pattern := range p.Evictable {
+		if matches(pattern, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func matches(pattern, key string) bool {
+	if strings.HasSuffix(pattern, "/") {
+		return strings.HasPrefix(key, pattern)
+	}
+	return key == pattern
+}
+
+// Prune removes evictable entries that have expired, then the least
+// recently used evictable entries until they fit in MaxSize. It returns the
+// removed keys.
+func Prune(store Store, p Policy) ([]string, error) {
+	infos, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	var kept []Info
+	var total int64
+	for _, info := range infos {
+		if info.Shared || !p.IsEvictable(info.Key) {
+			continue
+		}
+		if !p.Fresh(info.Key, info.Stored) {
+			if err := store.Delete(info.Key); err != nil {
+				return removed, err
+			}
+			removed = append(removed, info.Key)
+			continue
+		}
+		kept = append(kept, info)
+		total += info.Size
+	}
+
+	if p.MaxSize <= 0 || total <= p.MaxSize {
+		return removed, nil
+	}
+
+	sort.Slice(kept, func(i, j int) bool {
+		return kept[i].Accessed.Before(kept[j].Accessed)
+	})
+	for _, info := range kept {
+		if total <= p.MaxSize {
+			break
+		}
+		if err := store.Delete(info.Key); err != nil {
+			return removed, err
+		}
+		removed = append(removed, info.Key)
+		total -= info.Size
+	}
+	return removed, nil
+}
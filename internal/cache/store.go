@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned for keys with no usable entry, including entries
+// written in an incompatible format or schema
+var ErrNotFound = errors.New("cache miss")
+
+// Info describes a stored entry
+type Info struct {
+	Key      string
+	Size     int64     // encoded size in bytes
+	Stored   time.Time // when the data was fetched
+	Accessed time.Time // last read or write, for LRU eviction
+	Source   [32]byte  // SHA-256 of the source the data was built from, if recorded
+	Shared   bool      // read from a shared, read-only location
+	Path     string    // file holding the entry, for file-based stores
+}
+
+// Store persists cached data by key. Keys may contain "/" to group entries,
+// such as "detail/formula/jq". Stores don't expire anything; callers decide
+// from Info.Stored whether an entry is fresh enough (see Policy).
+type Store interface {
+	// Get decodes the entry for key into dest, whatever its age
+	Get(key string, dest any) (Info, error)
+	// Set stores data for key, recording the SHA-256 of the source it was
+	// built from (zero if unknown)
+	Set(key string, data any, source [32]byte) error
+	Delete(key string) error
+	// Stat describes the entry for key without decoding it
+	Stat(key string) (Info, error)
+	// List describes every entry, sorted by key
+	List() ([]Info, error)
+}
+
+// Versioned is implemented by stores that record a schema version with each
+// entry. Entries written with another schema are treated as missing.
+type Versioned interface {
+	SetSchema(schema uint32)
+}
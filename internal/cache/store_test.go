@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testData struct {
+	Name  string
+	Count int
+}
+
+// stores returns a fresh instance of every Store implementation
+func stores(t *testing.T) map[string]Store {
+	t.Helper()
+	bolt, err := NewBolt(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { bolt.Close() })
+
+	return map[string]Store{
+		"fs":     NewFS(t.TempDir()),
+		"memory": NewMemory(),
+		"bolt":   bolt,
+	}
+}
+
+func TestStore(t *testing.T) {
+	source := sha256.Sum256([]byte("formula.json"))
+
+	for name, store := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			if _, err := store.Get("jq", &testData{}); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get of a missing key = %v, want ErrNotFound", err)
+			}
+			if _, err := store.Stat("jq"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Stat of a missing key = %v, want ErrNotFound", err)
+			}
+
+			before := time.Now()
+			if err := store.Set("jq", testData{Name: "jq", Count: 3}, source); err != nil {
+				t.Fatal(err)
+			}
+			if err := store.Set("fd", testData{Name: "fd"}, [32]byte{}); err != nil {
+				t.Fatal(err)
+			}
+
+			var got testData
+			info, err := store.Get("jq", &got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != (testData{Name: "jq", Count: 3}) {
+				t.Errorf("Get decoded %+v", got)
+			}
+			if info.Key != "jq" || info.Source != source || info.Stored.Before(before.Add(-time.Second)) || info.Size == 0 {
+				t.Errorf("Get info = %+v", info)
+			}
+
+			stat, err := store.Stat("jq")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stat.Key != info.Key || stat.Source != info.Source || !stat.Stored.Equal(info.Stored) {
+				t.Errorf("Stat = %+v, want it to match Get's %+v", stat, info)
+			}
+
+			// Set replaces an existing entry
+			if err := store.Set("jq", testData{Name: "jq", Count: 4}, source); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.Get("jq", &got); err != nil || got.Count != 4 {
+				t.Errorf("Get after replacing = %+v, %v, want count 4", got, err)
+			}
+
+			list, err := store.List()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(list) != 2 || list[0].Key != "fd" || list[1].Key != "jq" {
+				t.Errorf("List = %+v, want fd and jq in order", list)
+			}
+
+			if err := store.Delete("jq"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.Get("jq", &got); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+			}
+			if err := store.Delete("jq"); err != nil {
+				t.Errorf("Delete of a missing key = %v", err)
+			}
+			if list, _ := store.List(); len(list) != 1 || list[0].Key != "fd" {
+				t.Errorf("List after Delete = %+v, want only fd", list)
+			}
+		})
+	}
+}
+
+func TestStoreSchemaMismatch(t *testing.T) {
+	for name, store := range stores(t) {
+		versioned, ok := store.(Versioned)
+		if !ok {
+			continue
+		}
+
+		t.Run(name, func(t *testing.T) {
+			versioned.SetSchema(1)
+			if err := store.Set("jq", testData{Name: "jq"}, [32]byte{}); err != nil {
+				t.Fatal(err)
+			}
+
+			// Data written for another version of the caller is missing
+			versioned.SetSchema(2)
+			if _, err := store.Get("jq", &testData{}); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get = %v, want ErrNotFound", err)
+			}
+			if _, err := store.Stat("jq"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Stat = %v, want ErrNotFound", err)
+			}
+			if list, err := store.List(); err != nil || len(list) != 0 {
+				t.Errorf("List = %+v, %v, want nothing", list, err)
+			}
+
+			versioned.SetSchema(1)
+			if _, err := store.Get("jq", &testData{}); err != nil {
+				t.Errorf("Get with the original schema = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,515 @@
+// Package config loads user settings from the config file and environment
+// and records where each effective value came from.
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Where a setting's value came from
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Config holds every user setting
+type Config struct {
+	Brewfiles      []string                 `toml:"brewfiles"`        // first is written to, the rest are only read
+	CacheDir       string                   `toml:"cache_dir"`        // where package data is cached
+	SharedCacheDir string                   `toml:"shared_cache_dir"` // read-only cache shared between users
+	CacheTTL       time.Duration            `toml:"cache_ttl"`        // how long cached data stays fresh
+	CacheTTLs      map[string]time.Duration `toml:"cache_ttls"`       // by cache key or "prefix/"; 0 never expires
+	CacheMaxSize   int64                    `toml:"cache_max_size"`   // bytes of evictable entries; 0 for the default
+	HistorySize    int                      `toml:"history_size"`     // earlier package indexes kept for whatsnew
+	Offline        bool                     `toml:"offline"`          // never use the network
+	Snapshot       []string                 `toml:"snapshot"`         // formula/cask JSON files used instead of the API
+	APIBaseURL     string                   `toml:"api_base_url"`     // serves formula.json and cask.json
+	DefaultMode    string                   `toml:"default_mode"`     // "bundle" or "immediate"
+	Sort           string                   `toml:"sort"`             // "length" or "name"
+	HiddenTypes    []string                 `toml:"hidden_types"`     // package types left out of results
+	ExtraTaps      []string                 `toml:"extra_taps"`       // installed taps whose packages are searched too
+	Keys           map[string]string        `toml:"keys"`             // action name to key
+	Theme          string                   `toml:"theme"`            // "emoji" or "ascii"
+}
+
+// Setting is one effective value and its origin, for display
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+	Origin string // file path, environment variable or flag name
+}
+
+// Loaded is the merged configuration with the origin of every value
+type Loaded struct {
+	Config
+	Path    string // config file location, whether or not it exists
+	origins map[string]Setting
+}
+
+// Modes for the interactive search
+const (
+	ModeBundle    = "bundle"
+	ModeImmediate = "immediate"
+)
+
+// DefaultAPIBaseURL is the public Homebrew formulae API
+const DefaultAPIBaseURL = "https://formulae.brew.sh/api"
+
+// Default returns the built-in settings
+func Default() Config {
+	home, _ := os.UserHomeDir()
+
+	cfg := Config{
+		CacheTTL:    24 * time.Hour,
+		HistorySize: 7,
+		APIBaseURL:  DefaultAPIBaseURL,
+		DefaultMode: ModeBundle,
+		Sort:        "length",
+		Theme:       "emoji",
+		Keys:        map[string]string{},
+		CacheTTLs:   map[string]time.Duration{},
+		CacheDir:    DefaultCacheDir(),
+	}
+	if home != "" {
+		cfg.Brewfiles = []string{filepath.Join(home, "Brewfile")}
+	}
+	return cfg
+}
+
+// DefaultCacheDir returns go-brew-search under $XDG_CACHE_HOME, or under the
+// platform cache directory when it is unset (~/.cache on Linux,
+// ~/Library/Caches on macOS)
+func DefaultCacheDir() string {
+	dir := os.Getenv("XDG_CACHE_HOME")
+	if !filepath.IsAbs(dir) {
+		var err error
+		if dir, err = os.UserCacheDir(); err != nil {
+			return ""
+		}
+	}
+	return filepath.Join(dir, "go-brew-search")
+}
+
+// DefaultPath returns $BREW_SEARCH_CONFIG, or config.toml under
+// $XDG_CONFIG_HOME (~/.config when unset)
+func DefaultPath() string {
+	if path := os.Getenv("BREW_SEARCH_CONFIG"); path != "" {
+		return path
+	}
+
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "go-brew-search", "config.toml")
+}
+
+// Load merges the defaults, the config file at path (if it exists) and the
+// environment
+func Load(path string) (*Loaded, error) {
+	l := &Loaded{
+		Config:  Default(),
+		Path:    path,
+		origins: make(map[string]Setting),
+	}
+	for _, key := range keys {
+		l.origins[key] = Setting{Key: key, Source: SourceDefault}
+	}
+
+	if err := l.loadFile(path); err != nil {
+		return nil, err
+	}
+	if err := l.loadEnv(os.Getenv); err != nil {
+		return nil, err
+	}
+	if err := l.validate(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// keys lists the settings in display order
+var keys = []string{
+	"brewfiles", "cache_dir", "shared_cache_dir", "cache_ttl", "cache_ttls",
+	"cache_max_size", "history_size", "offline",
+	"snapshot", "api_base_url", "default_mode",
+	"sort", "hidden_types", "extra_taps", "keys", "theme",
+}
+
+// fileConfig mirrors Config with durations and sizes as text, so they can be
+// written as "12h" or "200MB" in the file
+type fileConfig struct {
+	Config
+	CacheTTL     string            `toml:"cache_ttl"`
+	CacheTTLs    map[string]string `toml:"cache_ttls"`
+	CacheMaxSize string            `toml:"cache_max_size"`
+}
+
+func (l *Loaded) loadFile(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	var fc fileConfig
+	md, err := toml.DecodeFile(path, &fc)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return fmt.Errorf("unknown setting %q in %s", undecoded[0].String(), path)
+	}
+
+	for _, key := range md.Keys() {
+		name := key[0]
+		if len(key) > 1 && name != "keys" {
+			continue
+		}
+		if err := l.apply(name, fc, path); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// apply copies one setting from the file
+func (l *Loaded) apply(key string, fc fileConfig, path string) error {
+	switch key {
+	case "brewfiles":
+		l.Brewfiles = expandAll(fc.Brewfiles)
+	case "cache_dir":
+		l.CacheDir = expandHome(fc.Config.CacheDir)
+	case "shared_cache_dir":
+		l.SharedCacheDir = expandHome(fc.SharedCacheDir)
+	case "cache_ttl":
+		ttl, err := time.ParseDuration(fc.CacheTTL)
+		if err != nil {
+			return fmt.Errorf("invalid cache_ttl: %w", err)
+		}
+		l.CacheTTL = ttl
+	case "cache_ttls":
+		for key, value := range fc.CacheTTLs {
+			ttl, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid cache_ttls entry %q: %w", key, err)
+			}
+			l.CacheTTLs[key] = ttl
+		}
+	case "cache_max_size":
+		size, err := parseSize(fc.CacheMaxSize)
+		if err != nil {
+			return fmt.Errorf("invalid cache_max_size: %w", err)
+		}
+		l.CacheMaxSize = size
+	case "history_size":
+		l.HistorySize = fc.HistorySize
+	case "offline":
+		l.Offline = fc.Offline
+	case "snapshot":
+		l.Snapshot = expandAll(fc.Snapshot)
+	case "api_base_url":
+		l.APIBaseURL = fc.APIBaseURL
+	case "default_mode":
+		l.DefaultMode = fc.DefaultMode
+	case "sort":
+		l.Sort = fc.Sort
+	case "hidden_types":
+		l.HiddenTypes = fc.HiddenTypes
+	case "extra_taps":
+		l.ExtraTaps = fc.ExtraTaps
+	case "keys":
+		for action, key := range fc.Keys {
+			l.Keys[action] = key
+		}
+	case "theme":
+		l.Theme = fc.Theme
+	default:
+		return nil
+	}
+
+	l.origins[key] = Setting{Key: key, Source: SourceFile, Origin: path}
+	return nil
+}
+
+// envVars maps environment variables to the settings they override
+var envVars = []struct {
+	name string
+	key  string
+}{
+	{"BREW_SEARCH_BREWFILE", "brewfiles"},
+	{"BREW_SEARCH_CACHE_DIR", "cache_dir"},
+	{"BREW_SEARCH_SHARED_CACHE_DIR", "shared_cache_dir"},
+	{"BREW_SEARCH_CACHE_TTL", "cache_ttl"},
+	{"BREW_SEARCH_CACHE_MAX_SIZE", "cache_max_size"},
+	{"BREW_SEARCH_HISTORY_SIZE", "history_size"},
+	{"BREW_SEARCH_OFFLINE", "offline"},
+	{"BREW_SEARCH_SNAPSHOT", "snapshot"},
+	{"BREW_SEARCH_API_URL", "api_base_url"},
+	{"BREW_SEARCH_MODE", "default_mode"},
+	{"BREW_SEARCH_SORT", "sort"},
+	{"BREW_SEARCH_HIDE", "hidden_types"},
+	{"BREW_SEARCH_TAPS", "extra_taps"},
+	{"BREW_SEARCH_THEME", "theme"},
+}
+
+func (l *Loaded) loadEnv(getenv func(string) string) error {
+	for _, env := range envVars {
+		value := getenv(env.name)
+		if value == "" {
+			continue
+		}
+		if err := l.Set(env.key, value, SourceEnv, env.name); err != nil {
+			return fmt.Errorf("%s: %w", env.name, err)
+		}
+	}
+	return nil
+}
+
+// Set overrides a setting from a string value, as given in the environment
+// or on the command line. Lists are separated by commas, except Brewfiles
+// and snapshots, which use the OS path list separator.
+func (l *Loaded) Set(key, value, source, origin string) error {
+	switch key {
+	case "brewfiles":
+		l.Brewfiles = expandAll(filepath.SplitList(value))
+	case "cache_dir":
+		l.CacheDir = expandHome(value)
+	case "shared_cache_dir":
+		l.SharedCacheDir = expandHome(value)
+	case "cache_ttl":
+		ttl, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid cache_ttl: %w", err)
+		}
+		l.CacheTTL = ttl
+	case "cache_max_size":
+		size, err := parseSize(value)
+		if err != nil {
+			return fmt.Errorf("invalid cache_max_size: %w", err)
+		}
+		l.CacheMaxSize = size
+	case "history_size":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid history_size %q: must be a number", value)
+		}
+		l.HistorySize = n
+	case "offline":
+		offline, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid offline value %q: must be true or false", value)
+		}
+		l.Offline = offline
+	case "snapshot":
+		l.Snapshot = expandAll(filepath.SplitList(value))
+	case "api_base_url":
+		l.APIBaseURL = value
+	case "default_mode":
+		l.DefaultMode = value
+	case "sort":
+		l.Sort = value
+	case "hidden_types":
+		l.HiddenTypes = splitList(value)
+	case "extra_taps":
+		l.ExtraTaps = splitList(value)
+	case "theme":
+		l.Theme = value
+	default:
+		return fmt.Errorf("unknown setting %q", key)
+	}
+
+	l.origins[key] = Setting{Key: key, Source: source, Origin: origin}
+	return l.validate()
+}
+
+func (l *Loaded) validate() error {
+	switch l.DefaultMode {
+	case ModeBundle, ModeImmediate:
+	default:
+		return fmt.Errorf("invalid default_mode %q: must be bundle or immediate", l.DefaultMode)
+	}
+
+	switch l.Sort {
+	case "length", "name":
+	default:
+		return fmt.Errorf("invalid sort %q: must be length or name", l.Sort)
+	}
+
+	switch l.Theme {
+	case "emoji", "ascii":
+	default:
+		return fmt.Errorf("invalid theme %q: must be emoji or ascii", l.Theme)
+	}
+
+	for _, t := range l.HiddenTypes {
+		if t != "formula" && t != "cask" {
+			return fmt.Errorf("invalid hidden_types entry %q: must be formula or cask", t)
+		}
+	}
+
+	if l.CacheTTL <= 0 {
+		return fmt.Errorf("cache_ttl must be positive")
+	}
+	for key, ttl := range l.CacheTTLs {
+		if ttl < 0 {
+			return fmt.Errorf("cache_ttls entry %q must not be negative", key)
+		}
+	}
+	if l.HistorySize < 0 {
+		return fmt.Errorf("history_size must not be negative")
+	}
+	return nil
+}
+
+// Settings returns every effective setting with its origin
+func (l *Loaded) Settings() []Setting {
+	settings := make([]Setting, 0, len(keys))
+	for _, key := range keys {
+		s := l.origins[key]
+		s.Value = l.format(key)
+		settings = append(settings, s)
+	}
+	return settings
+}
+
+func (l *Loaded) format(key string) string {
+	switch key {
+	case "brewfiles":
+		return strings.Join(l.Brewfiles, ", ")
+	case "cache_dir":
+		return l.CacheDir
+	case "shared_cache_dir":
+		return l.SharedCacheDir
+	case "cache_ttl":
+		return l.CacheTTL.String()
+	case "cache_ttls":
+		patterns := make([]string, 0, len(l.CacheTTLs))
+		for pattern := range l.CacheTTLs {
+			patterns = append(patterns, pattern)
+		}
+		sort.Strings(patterns)
+		for i, pattern := range patterns {
+			patterns[i] = pattern + "=" + l.CacheTTLs[pattern].String()
+		}
+		return strings.Join(patterns, ", ")
+	case "cache_max_size":
+		if l.CacheMaxSize == 0 {
+			return ""
+		}
+		return strconv.FormatInt(l.CacheMaxSize, 10)
+	case "history_size":
+		return strconv.Itoa(l.HistorySize)
+	case "offline":
+		return strconv.FormatBool(l.Offline)
+	case "snapshot":
+		return strings.Join(l.Snapshot, ", ")
+	case "api_base_url":
+		return l.APIBaseURL
+	case "default_mode":
+		return l.DefaultMode
+	case "sort":
+		return l.Sort
+	case "hidden_types":
+		return strings.Join(l.HiddenTypes, ", ")
+	case "extra_taps":
+		return strings.Join(l.ExtraTaps, ", ")
+	case "keys":
+		actions := make([]string, 0, len(l.Keys))
+		for action := range l.Keys {
+			actions = append(actions, action)
+		}
+		sort.Strings(actions)
+		for i, action := range actions {
+			actions[i] = action + "=" + l.Keys[action]
+		}
+		return strings.Join(actions, ", ")
+	case "theme":
+		return l.Theme
+	}
+	return ""
+}
+
+// Hidden reports whether packages of the given type are hidden
+func (c Config) Hidden(pkgType string) bool {
+	for _, t := range c.HiddenTypes {
+		if t == pkgType {
+			return true
+		}
+	}
+	return false
+}
+
+// parseSize reads a byte count such as "200MB", "1.5GB" or "4096"
+func parseSize(value string) (int64, error) {
+	units := []struct {
+		suffix string
+		size   float64
+	}{
+		{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1},
+	}
+
+	text := strings.ToUpper(strings.TrimSpace(value))
+	multiplier := 1.0
+	for _, unit := range units {
+		if strings.HasSuffix(text, unit.suffix) {
+			text = strings.TrimSpace(strings.TrimSuffix(text, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%q is not a size like 200MB", value)
+	}
+	return int64(n * multiplier), nil
+}
+
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func expandAll(paths []string) []string {
+	result := make([]string, len(paths))
+	for i, path := range paths {
+		result[i] = expandHome(path)
+	}
+	return result
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
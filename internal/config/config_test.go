@@ -0,0 +1,208 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes a config file and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// clearEnv unsets the variables Load reads for the rest of the test
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, env := range envVars {
+		t.Setenv(env.name, "")
+	}
+}
+
+func settingsByKey(l *Loaded) map[string]Setting {
+	byKey := make(map[string]Setting)
+	for _, s := range l.Settings() {
+		byKey[s.Key] = s
+	}
+	return byKey
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, `
+sort = "name"
+theme = "ascii"
+cache_ttl = "12h"
+hidden_types = ["cask"]
+
+[keys]
+add = "ctrl-b"
+`)
+	t.Setenv("BREW_SEARCH_SORT", "length")
+	t.Setenv("BREW_SEARCH_TAPS", "user/tools, other/extra")
+
+	l, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Set("theme", "emoji", SourceFlag, "-theme"); err != nil {
+		t.Fatal(err)
+	}
+
+	if l.CacheTTL != 12*time.Hour || l.Sort != "length" || l.Theme != "emoji" || l.Keys["add"] != "ctrl-b" {
+		t.Errorf("config = %+v", l.Config)
+	}
+	if got := strings.Join(l.ExtraTaps, " "); got != "user/tools other/extra" {
+		t.Errorf("ExtraTaps = %q", l.ExtraTaps)
+	}
+	if !l.Hidden("cask") || l.Hidden("formula") {
+		t.Errorf("HiddenTypes = %q, want only casks hidden", l.HiddenTypes)
+	}
+
+	settings := settingsByKey(l)
+	tests := []struct {
+		key, value, source, origin string
+	}{
+		{"cache_ttl", "12h0m0s", SourceFile, path},        // file only
+		{"sort", "length", SourceEnv, "BREW_SEARCH_SORT"}, // env beats file
+		{"theme", "emoji", SourceFlag, "-theme"},          // flag beats file
+		{"extra_taps", "user/tools, other/extra", SourceEnv, "BREW_SEARCH_TAPS"},
+		{"keys", "add=ctrl-b", SourceFile, path},
+		{"default_mode", ModeBundle, SourceDefault, ""},
+	}
+	for _, tt := range tests {
+		s := settings[tt.key]
+		if s.Value != tt.value || s.Source != tt.source || s.Origin != tt.origin {
+			t.Errorf("%s = %q from %s %q, want %q from %s %q", tt.key, s.Value, s.Source, s.Origin, tt.value, tt.source, tt.origin)
+		}
+	}
+}
+
+func TestLoadCacheTTLs(t *testing.T) {
+	clearEnv(t)
+	l, err := Load(writeConfig(t, `
+[cache_ttls]
+"detail/" = "72h"
+"formulae" = "0"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 0 means the entries never expire, as in cache.Policy
+	want := map[string]time.Duration{"detail/": 72 * time.Hour, "formulae": 0}
+	if !reflect.DeepEqual(l.CacheTTLs, want) {
+		t.Errorf("CacheTTLs = %v, want %v", l.CacheTTLs, want)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	clearEnv(t)
+	l, err := Load(filepath.Join(t.TempDir(), "none.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range l.Settings() {
+		if s.Source != SourceDefault {
+			t.Errorf("%s comes from %s, want the default", s.Key, s.Source)
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		env     map[string]string
+	}{
+		{name: "unknown setting", content: `colour = "red"`},
+		{name: "bad toml", content: `sort = `},
+		{name: "bad mode", content: `default_mode = "later"`},
+		{name: "bad sort", content: `sort = "size"`},
+		{name: "bad theme", content: `theme = "neon"`},
+		{name: "bad hidden type", content: `hidden_types = ["tap"]`},
+		{name: "bad ttl", content: `cache_ttl = "soon"`},
+		{name: "negative ttl", content: `cache_ttl = "-1h"`},
+		{name: "negative key ttl", content: "[cache_ttls]\n\"detail/\" = \"-1h\""},
+		{name: "bad key ttl", content: "[cache_ttls]\n\"detail/\" = \"weekly\""},
+		{name: "bad env", env: map[string]string{"BREW_SEARCH_MODE": "later"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			if _, err := Load(writeConfig(t, tt.content)); err == nil {
+				t.Error("Load succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestSetInvalid(t *testing.T) {
+	clearEnv(t)
+	l, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Set("sort", "size", SourceFlag, "-sort"); err == nil {
+		t.Error("Set accepted an invalid sort")
+	}
+	if err := l.Set("colour", "red", SourceFlag, "-colour"); err == nil {
+		t.Error("Set accepted an unknown setting")
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		path, want string
+	}{
+		{"~", home},
+		{"~/Brewfile", filepath.Join(home, "Brewfile")},
+		{"~user/Brewfile", "~user/Brewfile"},
+		{"/etc/Brewfile", "/etc/Brewfile"},
+	}
+	for _, tt := range tests {
+		if got := expandHome(tt.path); got != tt.want {
+			t.Errorf("expandHome(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "0", want: 0},
+		{value: "512", want: 512},
+		{value: "512B", want: 512},
+		{value: "64KB", want: 64 << 10},
+		{value: "200MB", want: 200 << 20},
+		{value: "200 mb", want: 200 << 20},
+		{value: "1.5GB", want: 3 << 29},
+		{value: "", wantErr: true},
+		{value: "MB", wantErr: true},
+		{value: "-1MB", wantErr: true},
+		{value: "200TB", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseSize(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseSize(%q) = %d, %v, want %d (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numbered returns lines brew "p1" to brew "pN"
+func numbered(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("brew \"p%d\"\n", i+1)
+	}
+	return lines
+}
+
+func TestUnified(t *testing.T) {
+	long := numbered(12)
+	longChanged := append(append([]string{long[0]}, long[2:10]...), "brew \"x\"\n", long[11])
+
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{
+			name: "identical",
+			old:  "brew \"jq\"\n",
+			new:  "brew \"jq\"\n",
+			want: "",
+		},
+		{
+			name: "insertion",
+			old:  "tap \"a/b\"\nbrew \"jq\"\nbrew \"wget\"\n",
+			new:  "tap \"a/b\"\nbrew \"fd\"\nbrew \"jq\"\nbrew \"wget\"\n",
+			want: `--- Brewfile
++++ Brewfile (new)
+@@ -1,3 +1,4 @@
+ tap "a/b"
++brew "fd"
+ brew "jq"
+ brew "wget"
+`,
+		},
+		{
+			name: "separate hunks",
+			old:  strings.Join(long, ""),
+			new:  strings.Join(longChanged, ""),
+			want: `--- Brewfile
++++ Brewfile (new)
+@@ -1,5 +1,4 @@
+ brew "p1"
+-brew "p2"
+ brew "p3"
+ brew "p4"
+ brew "p5"
+@@ -8,5 +7,5 @@
+ brew "p8"
+ brew "p9"
+ brew "p10"
+-brew "p11"
++brew "x"
+ brew "p12"
+`,
+		},
+		{
+			name: "new file",
+			old:  "",
+			new:  "brew \"jq\"\n",
+			want: `--- Brewfile
++++ Brewfile (new)
+@@ -0,0 +1 @@
++brew "jq"
+`,
+		},
+		{
+			name: "emptied file",
+			old:  "brew \"jq\"\ncask \"firefox\"\n",
+			new:  "",
+			want: `--- Brewfile
++++ Brewfile (new)
+@@ -1,2 +0,0 @@
+-brew "jq"
+-cask "firefox"
+`,
+		},
+		{
+			name: "missing final newline",
+			old:  "brew \"jq\"",
+			new:  "brew \"jq\"\n",
+			want: `--- Brewfile
++++ Brewfile (new)
+@@ -1 +1 @@
+-brew "jq"
+\ No newline at end of file
++brew "jq"
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Unified("Brewfile", "Brewfile (new)", tt.old, tt.new); got != tt.want {
+				t.Errorf("Unified =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package search
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/cache"
+)
+
+// IndexKey is the cache key the full-text index is stored under
+const IndexKey = "search-index"
+
+// indexVersion changes whenever Index or the tokenizer does, so stored
+// indexes are rebuilt. It is part of the fingerprint.
+const indexVersion = 1
+
+// BM25 parameters: k1 limits how much repeated terms count, b how much
+// long documents are penalized
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Field weights: a term in the name says more than one in the description
+const (
+	weightName        = 3
+	weightFullName    = 2
+	weightAlias       = 2
+	weightDescription = 1
+)
+
+// stopWords are too common in descriptions to help ranking
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"by": true, "for": true, "from": true, "in": true, "is": true, "it": true, "of": true,
+	"on": true, "or": true, "that": true, "the": true, "this": true, "to": true,
+	"with": true, "your": true, "you": true,
+}
+
+// Index is an inverted index over package names, full names, aliases and
+// descriptions, ranked with BM25
+type Index struct {
+	Docs     []string // "type:token" of each package
+	Lengths  []float32
+	AvgLen   float32
+	Postings map[string][]Posting // by stemmed term
+}
+
+// Posting is one package containing a term, with its weighted frequency
+type Posting struct {
+	Doc  uint32
+	Freq float32
+}
+
+// Hit is a package matching a discover query
+type Hit struct {
+	Key   string // "type:token"
+	Score float64
+}
+
+// BuildIndex indexes packages
+func BuildIndex(packages []api.Package) *Index {
+	ix := &Index{
+		Docs:     make([]string, len(packages)),
+		Lengths:  make([]float32, len(packages)),
+		Postings: make(map[string][]Posting),
+	}
+
+	var total float64
+	freqs := make(map[string]float32)
+	for i, pkg := range packages {
+		ix.Docs[i] = docKey(pkg)
+
+		clear(freqs)
+		var length float32
+		add := func(text string, weight float32) {
+			for _, term := range Terms(text) {
+				freqs[term] += weight
+				length += weight
+			}
+		}
+		add(pkg.Token, weightName)
+		if pkg.FullName != pkg.Token {
+			add(pkg.FullName, weightFullName)
+		}
+		for _, alias := range pkg.Aliases {
+			add(alias, weightAlias)
+		}
+		add(pkg.Description, weightDescription)
+
+		for term, freq := range freqs {
+			ix.Postings[term] = append(ix.Postings[term], Posting{Doc: uint32(i), Freq: freq})
+		}
+		ix.Lengths[i] = length
+		total += float64(length)
+	}
+
+	if len(packages) > 0 {
+		ix.AvgLen = float32(total / float64(len(packages)))
+	}
+	return ix
+}
+
+// Search returns the packages matching any of the query's terms, best
+// first
+func (ix *Index) Search(text string) []Hit {
+	scores := make(map[uint32]float64)
+	n := float64(len(ix.Docs))
+	for _, term := range uniqueTerms(text) {
+		postings := ix.Postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			tf := float64(p.Freq)
+			norm := 1 - bm25B + bm25B*float64(ix.Lengths[p.Doc])/float64(ix.AvgLen)
+			scores[p.Doc] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for doc, score := range scores {
+		hits = append(hits, Hit{Key: ix.Docs[doc], Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Key < hits[j].Key
+	})
+	return hits
+}
+
+// Terms splits text into lower-case, stemmed terms without stop words.
+// Words are split at anything but letters and digits, so "python@3.13"
+// gives "python", "3" and "13".
+func Terms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := words[:0]
+	for _, word := range words {
+		if !stopWords[word] {
+			terms = append(terms, stem(word))
+		}
+	}
+	return terms
+}
+
+func uniqueTerms(text string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, term := range Terms(text) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+func docKey(pkg api.Package) string {
+	return pkg.Type + ":" + pkg.Token
+}
+
+// fingerprint identifies the indexed content of packages regardless of
+// their order, so an unchanged package list reuses the stored index
+func fingerprint(packages []api.Package) [32]byte {
+	var sum uint64
+	h := fnv.New64a()
+	for _, pkg := range packages {
+		h.Reset()
+		for _, s := range append([]string{pkg.Type, pkg.Token, pkg.FullName, pkg.Description}, pkg.Aliases...) {
+			h.Write([]byte(s))
+			h.Write([]byte{0})
+		}
+		sum += h.Sum64()
+	}
+
+	var fp [32]byte
+	binary.LittleEndian.PutUint64(fp[0:], sum)
+	binary.LittleEndian.PutUint64(fp[8:], uint64(len(packages)))
+	binary.LittleEndian.PutUint64(fp[16:], indexVersion)
+	return fp
+}
+
+// CachedIndex returns the index of packages from store, building and
+// storing it when the packages changed since it was last built. A failure
+// to store it only costs a rebuild next time.
+func CachedIndex(store cache.Store, packages []api.Package) *Index {
+	fp := fingerprint(packages)
+
+	if info, err := store.Stat(IndexKey); err == nil && info.Source == fp {
+		var ix Index
+		if _, err := store.Get(IndexKey, &ix); err == nil {
+			return &ix
+		}
+	}
+
+	ix := BuildIndex(packages)
+	store.Set(IndexKey, ix, fp)
+	return ix
+}
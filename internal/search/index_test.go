@@ -0,0 +1,133 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/cache"
+)
+
+var indexPackages = []api.Package{
+	{Token: "jq", Type: "formula", Description: "Lightweight and flexible command-line JSON processor"},
+	{Token: "yq", Type: "formula", Description: "Process YAML, JSON, XML and CSV documents"},
+	{Token: "pandoc", Type: "formula", Description: "Swiss-army knife of markup format conversion"},
+	{Token: "ffmpeg", Type: "formula", Description: "Play, record, convert, and stream audio and video"},
+	{Token: "iterm2", Type: "cask", FullName: "iTerm2", Description: "Terminal emulator as alternative to Apple's Terminal app"},
+	{Token: "python@3.13", Type: "formula", Aliases: []string{"python3"}, Description: "Interpreted, interactive, object-oriented programming language"},
+}
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Converting the videos", []string{"convert", "video"}},
+		{"python@3.13", []string{"python", "3", "13"}},
+		{"a tool for you", []string{"tool"}},
+		{"", nil},
+	}
+	for _, tt := range tests {
+		got := Terms(tt.text)
+		if len(got) == 0 && len(tt.want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Terms(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestBuildIndex(t *testing.T) {
+	ix := BuildIndex(indexPackages)
+
+	if len(ix.Docs) != len(indexPackages) || ix.Docs[4] != "cask:iterm2" {
+		t.Fatalf("Docs = %q", ix.Docs)
+	}
+	if ix.AvgLen <= 0 {
+		t.Errorf("AvgLen = %v, want the mean document length", ix.AvgLen)
+	}
+
+	// The name counts three times, the description once
+	if postings := ix.Postings["jq"]; len(postings) != 1 || postings[0].Freq != weightName {
+		t.Errorf("postings of jq = %+v, want one with frequency %d", postings, weightName)
+	}
+	if postings := ix.Postings["python3"]; len(postings) != 1 || postings[0].Freq != weightAlias {
+		t.Errorf("postings of python3 = %+v, want the alias", postings)
+	}
+	if _, ok := ix.Postings["the"]; ok {
+		t.Error("stop word indexed")
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	ix := BuildIndex(indexPackages)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"json", []string{"formula:jq", "formula:yq"}},
+		{"converter", []string{"formula:ffmpeg"}},
+		{"formats", []string{"formula:pandoc"}},
+		{"terminal", []string{"cask:iterm2"}},
+		{"python3", []string{"formula:python@3.13"}},
+		{"the and of", nil},
+		{"nothing-matches", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, hit := range ix.Search(tt.query) {
+			got = append(got, hit.Key)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	ix := BuildIndex(indexPackages)
+	q, err := ParseQuery("json -yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := Discover(ix, indexPackages, q, Options{})
+	if len(results) != 1 || results[0].Package.Token != "jq" || results[0].Score <= 0 {
+		t.Errorf("Discover = %+v, want only jq", results)
+	}
+}
+
+func TestCachedIndex(t *testing.T) {
+	store := cache.NewMemory()
+
+	first := CachedIndex(store, indexPackages)
+	info, err := store.Stat(IndexKey)
+	if err != nil {
+		t.Fatalf("index not stored: %v", err)
+	}
+
+	// The same packages in another order reuse the stored index
+	reversed := make([]api.Package, len(indexPackages))
+	for i, pkg := range indexPackages {
+		reversed[len(reversed)-1-i] = pkg
+	}
+	if fingerprint(reversed) != info.Source {
+		t.Error("fingerprint depends on package order")
+	}
+	if second := CachedIndex(store, reversed); !reflect.DeepEqual(second.Docs, first.Docs) {
+		t.Errorf("Docs = %q, want the stored index's %q", second.Docs, first.Docs)
+	}
+
+	// A changed description rebuilds it
+	changed := append([]api.Package(nil), indexPackages...)
+	changed[0].Description = "JSON query tool"
+	if fingerprint(changed) == info.Source {
+		t.Error("fingerprint ignores description changes")
+	}
+	CachedIndex(store, changed)
+	if after, _ := store.Stat(IndexKey); after.Source == info.Source {
+		t.Error("index not rebuilt after the packages changed")
+	}
+}
@@ -0,0 +1,257 @@
+package search
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/brew"
+)
+
+// Fields lists the qualifiers a query understands, as in "type:cask"
+var Fields = []string{"type", "license", "tap", "dep", "desc", "in", "deprecated", "version"}
+
+// Query is a parsed search query: qualifiers that packages must satisfy
+// and free-text terms that are matched and ranked
+type Query struct {
+	Terms    []string // free text, lower case
+	Excluded []string // free text after "-", lower case
+	Filters  []Filter
+}
+
+// Filter is one qualifier, such as `license:MIT` or `-in:brewfile`
+type Filter struct {
+	Field  string
+	Op     string // for version: "=", ">", ">=", "<" or "<="; empty for a prefix match
+	Value  string
+	Negate bool
+}
+
+// ParseQuery splits a query into qualifiers and free-text terms. Values
+// and terms with spaces are written in double quotes, as in
+// desc:"json parser". A leading "-" negates a qualifier or excludes a term.
+func ParseQuery(s string) (Query, error) {
+	words, err := splitQuery(s)
+	if err != nil {
+		return Query{}, err
+	}
+
+	var q Query
+	for _, word := range words {
+		if word.literal {
+			q.Terms = append(q.Terms, strings.ToLower(word.text))
+			continue
+		}
+
+		negate := false
+		text := word.text
+		if strings.HasPrefix(text, "-") && len(text) > 1 {
+			negate = true
+			text = text[1:]
+		}
+
+		field, value, ok := strings.Cut(text, ":")
+		if !ok || !isFieldName(field) {
+			term := strings.ToLower(text)
+			if negate {
+				q.Excluded = append(q.Excluded, term)
+			} else {
+				q.Terms = append(q.Terms, term)
+			}
+			continue
+		}
+
+		f, err := parseFilter(strings.ToLower(field), value, negate)
+		if err != nil {
+			return Query{}, err
+		}
+		q.Filters = append(q.Filters, f)
+	}
+	return q, nil
+}
+
+// Empty reports whether the query has no qualifiers or terms
+func (q Query) Empty() bool {
+	return len(q.Terms) == 0 && len(q.Excluded) == 0 && len(q.Filters) == 0
+}
+
+// Text returns the free-text terms joined by spaces
+func (q Query) Text() string {
+	return strings.Join(q.Terms, " ")
+}
+
+// Match reports whether pkg satisfies every qualifier and excluded term.
+// Free-text terms are left to Rank or the fuzzy finder. existing holds the
+// Brewfile's tokens, for in:brewfile.
+func (q Query) Match(pkg api.Package, existing map[string]bool) bool {
+	for _, f := range q.Filters {
+		if f.match(pkg, existing) == f.Negate {
+			return false
+		}
+	}
+	for _, term := range q.Excluded {
+		if strings.Contains(strings.ToLower(pkg.Token), term) || strings.Contains(strings.ToLower(pkg.Description), term) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the packages that satisfy the qualifiers and excluded
+// terms of q, keeping their order
+func (q Query) Filter(packages []api.Package, existing map[string]bool) []api.Package {
+	if len(q.Filters) == 0 && len(q.Excluded) == 0 {
+		return packages
+	}
+
+	var result []api.Package
+	for _, pkg := range packages {
+		if q.Match(pkg, existing) {
+			result = append(result, pkg)
+		}
+	}
+	return result
+}
+
+func parseFilter(field, value string, negate bool) (Filter, error) {
+	f := Filter{Field: field, Value: value, Negate: negate}
+	if value == "" {
+		return f, fmt.Errorf("%s: needs a value", field)
+	}
+
+	switch field {
+	case "type":
+		f.Value = strings.ToLower(value)
+		if f.Value != "formula" && f.Value != "cask" {
+			return f, fmt.Errorf("type:%s: must be formula or cask", value)
+		}
+	case "in":
+		f.Value = strings.ToLower(value)
+		if f.Value != "brewfile" {
+			return f, fmt.Errorf("in:%s: only in:brewfile is supported", value)
+		}
+	case "deprecated":
+		switch strings.ToLower(value) {
+		case "true", "yes":
+			f.Value = "true"
+		case "false", "no":
+			f.Value = "false"
+		default:
+			return f, fmt.Errorf("deprecated:%s: must be true or false", value)
+		}
+	case "version":
+		for _, op := range []string{">=", "<=", ">", "<", "="} {
+			if rest, ok := strings.CutPrefix(value, op); ok {
+				f.Op, f.Value = op, rest
+				break
+			}
+		}
+		if f.Value == "" {
+			return f, fmt.Errorf("version:%s: needs a version after %s", value, f.Op)
+		}
+	}
+	return f, nil
+}
+
+func (f Filter) match(pkg api.Package, existing map[string]bool) bool {
+	switch f.Field {
+	case "type":
+		return pkg.Type == f.Value
+	case "license":
+		return containsFold(pkg.License, f.Value)
+	case "tap":
+		return strings.EqualFold(pkg.Tap, f.Value)
+	case "dep":
+		return slices.ContainsFunc(pkg.Dependencies, func(dep string) bool {
+			return strings.EqualFold(dep, f.Value)
+		})
+	case "desc":
+		return containsFold(pkg.Description, f.Value)
+	case "in":
+		return existing[pkg.Token]
+	case "deprecated":
+		return pkg.Deprecated == (f.Value == "true")
+	case "version":
+		return matchVersion(pkg.Version, f.Op, f.Value)
+	}
+	return false
+}
+
+// matchVersion compares a package version with a qualifier's. Without an
+// operator, the version must start with the given components, so 3.13
+// matches 3.13.1 but not 3.130.
+func matchVersion(version, op, value string) bool {
+	if version == "" || version == brew.Unversioned {
+		return false
+	}
+
+	c := brew.CompareVersions(version, value)
+	switch op {
+	case "=":
+		return c == 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+
+	if !strings.HasPrefix(version, value) {
+		return false
+	}
+	rest := version[len(value):]
+	return rest == "" || strings.IndexAny(rest[:1], ".,_-") == 0
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func isFieldName(name string) bool {
+	return slices.Contains(Fields, strings.ToLower(name))
+}
+
+// queryWord is a whitespace-separated part of a query with its quotes
+// removed
+type queryWord struct {
+	text    string
+	literal bool // started with a quote, so it is free text as written
+}
+
+// splitQuery splits a query at whitespace outside double quotes
+func splitQuery(s string) ([]queryWord, error) {
+	var words []queryWord
+	var text strings.Builder
+	literal, inQuotes := false, false
+
+	flush := func() {
+		if text.Len() > 0 {
+			words = append(words, queryWord{text: text.String(), literal: literal})
+		}
+		text.Reset()
+		literal = false
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"':
+			if text.Len() == 0 && !inQuotes {
+				literal = true
+			}
+			inQuotes = !inQuotes
+		case !inQuotes && (c == ' ' || c == '\t' || c == '\n'):
+			flush()
+		default:
+			text.WriteByte(c)
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in query %q", s)
+	}
+	flush()
+	return words, nil
+}
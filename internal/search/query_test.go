@@ -0,0 +1,133 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/user/go-brew-search/internal/api"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  Query
+	}{
+		{"", Query{}},
+		{"JSON  Parser", Query{Terms: []string{"json", "parser"}}},
+		{"type:cask browser", Query{
+			Terms:   []string{"browser"},
+			Filters: []Filter{{Field: "type", Value: "cask"}},
+		}},
+		{"Type:Formula", Query{Filters: []Filter{{Field: "type", Value: "formula"}}}},
+		{`desc:"json parser" -in:brewfile`, Query{Filters: []Filter{
+			{Field: "desc", Value: "json parser"},
+			{Field: "in", Value: "brewfile", Negate: true},
+		}}},
+		{`"type:cask" "two words"`, Query{Terms: []string{"type:cask", "two words"}}},
+		{"-gui editor", Query{Terms: []string{"editor"}, Excluded: []string{"gui"}}},
+		{"- c++", Query{Terms: []string{"-", "c++"}}},
+		{"http://example.com", Query{Terms: []string{"http://example.com"}}},
+		{"deprecated:yes", Query{Filters: []Filter{{Field: "deprecated", Value: "true"}}}},
+		{"version:3.13", Query{Filters: []Filter{{Field: "version", Value: "3.13"}}}},
+		{"version:>=1.7", Query{Filters: []Filter{{Field: "version", Op: ">=", Value: "1.7"}}}},
+		{"version:<2", Query{Filters: []Filter{{Field: "version", Op: "<", Value: "2"}}}},
+		{"version:=1.0.0", Query{Filters: []Filter{{Field: "version", Op: "=", Value: "1.0.0"}}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", tt.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseQuery(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryInvalid(t *testing.T) {
+	for _, query := range []string{
+		`desc:"json`,
+		`"unterminated`,
+		"type:",
+		"type:bottle",
+		"in:history",
+		"deprecated:maybe",
+		"version:>=",
+		"version:<",
+	} {
+		if q, err := ParseQuery(query); err == nil {
+			t.Errorf("ParseQuery(%q) = %+v, want an error", query, q)
+		}
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	jq := api.Package{
+		Token:        "jq",
+		Type:         "formula",
+		Description:  "Lightweight and flexible command-line JSON processor",
+		Version:      "1.7.1",
+		License:      "MIT",
+		Tap:          "homebrew/core",
+		Dependencies: []string{"oniguruma"},
+	}
+	existing := map[string]bool{"jq": true}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"type:formula", true},
+		{"-type:formula", false},
+		{"license:mit", true},
+		{"tap:Homebrew/Core", true},
+		{"dep:oniguruma", true},
+		{"dep:onig", false},
+		{`desc:"json processor"`, true},
+		{"in:brewfile", true},
+		{"deprecated:false", true},
+		{"-json", false},
+		{"-yaml", true},
+		{"version:1.7", true},
+		{"version:1.71", false},
+		{"version:>1.7", true},
+		{"version:>=1.8", false},
+		{"version:<1.10", true},
+		{"version:=1.7.1", true},
+		{"type:formula license:gpl", false},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q): %v", tt.query, err)
+		}
+		if got := q.Match(jq, existing); got != tt.want {
+			t.Errorf("%q matches jq = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMatchVersion(t *testing.T) {
+	tests := []struct {
+		version, op, value string
+		want               bool
+	}{
+		{"3.13.1", "", "3.13", true},
+		{"3.13", "", "3.13", true},
+		{"3.130", "", "3.13", false},
+		{"3.13_1", "", "3.13", true},
+		{"1.2.3", "=", "1.2.3", true},
+		{"1.10", ">", "1.9", true},
+		{"1.9", ">", "1.10", false},
+		{"2.0", ">=", "2", true},
+		{"1.0-beta", "<", "1.0", true},
+		{"latest", ">", "0", false},
+		{"", "", "1", false},
+	}
+	for _, tt := range tests {
+		if got := matchVersion(tt.version, tt.op, tt.value); got != tt.want {
+			t.Errorf("matchVersion(%q, %q, %q) = %v, want %v", tt.version, tt.op, tt.value, got, tt.want)
+		}
+	}
+}
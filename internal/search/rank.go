@@ -0,0 +1,153 @@
+// Package search ranks cached packages against a query without the
+// interactive finder.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/user/go-brew-search/internal/api"
+)
+
+// Options control which packages match and how many are returned
+type Options struct {
+	Type  string // "formula", "cask" or empty for both
+	Exact bool   // match terms as substrings only, without fuzzy matching
+	Limit int    // maximum results; 0 means no limit
+
+	Existing map[string]bool // tokens in the Brewfile, for in:brewfile
+}
+
+// Result is a matching package and its relevance score
+type Result struct {
+	Package api.Package
+	Score   int
+}
+
+// Scores for where a query term matched, best first
+const (
+	scoreExactName   = 100
+	scoreNamePrefix  = 60
+	scoreNameSubstr  = 40
+	scoreFullName    = 30
+	scoreFuzzyName   = 20
+	scoreDescription = 10
+)
+
+// Rank returns the packages that satisfy the qualifiers of q and match
+// every free-text term, most relevant first. An empty query matches
+// everything.
+func Rank(packages []api.Package, q Query, opts Options) []Result {
+	var results []Result
+	for _, pkg := range packages {
+		if opts.Type != "" && pkg.Type != opts.Type {
+			continue
+		}
+		if !q.Match(pkg, opts.Existing) {
+			continue
+		}
+
+		score, ok := scorePackage(pkg, q.Terms, opts.Exact)
+		if !ok {
+			continue
+		}
+		results = append(results, Result{Package: pkg, Score: score})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		// Shorter tokens are more likely to be what was searched for
+		if len(a.Package.Token) != len(b.Package.Token) {
+			return len(a.Package.Token) < len(b.Package.Token)
+		}
+		if a.Package.Token != b.Package.Token {
+			return a.Package.Token < b.Package.Token
+		}
+		return a.Package.Type < b.Package.Type
+	})
+
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+
+	return results
+}
+
+// Discover ranks packages by how relevant their names and descriptions
+// are to the free text of q, using ix, and keeps those that satisfy its
+// qualifiers. Without free text it falls back to Rank.
+func Discover(ix *Index, packages []api.Package, q Query, opts Options) []Result {
+	if len(q.Terms) == 0 {
+		return Rank(packages, q, opts)
+	}
+
+	byKey := make(map[string]api.Package, len(packages))
+	for _, pkg := range packages {
+		byKey[docKey(pkg)] = pkg
+	}
+
+	var results []Result
+	for _, hit := range ix.Search(q.Text()) {
+		pkg, ok := byKey[hit.Key]
+		if !ok || opts.Type != "" && pkg.Type != opts.Type || !q.Match(pkg, opts.Existing) {
+			continue
+		}
+		results = append(results, Result{Package: pkg, Score: int(math.Round(hit.Score * 100))})
+		if opts.Limit > 0 && len(results) == opts.Limit {
+			break
+		}
+	}
+	return results
+}
+
+func scorePackage(pkg api.Package, terms []string, exact bool) (int, bool) {
+	token := strings.ToLower(pkg.Token)
+	fullName := strings.ToLower(pkg.FullName)
+	desc := strings.ToLower(pkg.Description)
+
+	total := 0
+	for _, term := range terms {
+		score := scoreTerm(term, token, fullName, desc, exact)
+		if score == 0 {
+			return 0, false
+		}
+		total += score
+	}
+	return total, true
+}
+
+func scoreTerm(term, token, fullName, desc string, exact bool) int {
+	switch {
+	case token == term:
+		return scoreExactName
+	case strings.HasPrefix(token, term):
+		return scoreNamePrefix
+	case strings.Contains(token, term):
+		return scoreNameSubstr
+	case fullName != "" && strings.Contains(fullName, term):
+		return scoreFullName
+	case !exact && isSubsequence(term, token):
+		return scoreFuzzyName
+	case strings.Contains(desc, term):
+		return scoreDescription
+	}
+	return 0
+}
+
+// isSubsequence reports whether the runes of term appear in s in order
+func isSubsequence(term, s string) bool {
+	remaining := []rune(term)
+	for _, r := range s {
+		if len(remaining) == 0 {
+			break
+		}
+		if r == remaining[0] {
+			remaining = remaining[1:]
+		}
+	}
+	return len(remaining) == 0
+}
@@ -0,0 +1,103 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/user/go-brew-search/internal/api"
+)
+
+var testPackages = []api.Package{
+	{Token: "jq", Type: "formula", Description: "Lightweight and flexible command-line JSON processor"},
+	{Token: "jqp", Type: "formula", Description: "TUI playground to experiment with jq"},
+	{Token: "gojq", Type: "formula", Description: "Pure Go implementation of jq"},
+	{Token: "jless", Type: "formula", Description: "Command-line pager for JSON data"},
+	{Token: "jq", Type: "cask", Description: "Cask with the same token"},
+	{Token: "fx", Type: "formula", Description: "Terminal JSON viewer"},
+	{Token: "firefox", Type: "cask", FullName: "Mozilla Firefox", Description: "Web browser"},
+	{Token: "json-query", Type: "formula", Description: "Query tool"},
+}
+
+func rankTokens(results []Result) []string {
+	var tokens []string
+	for _, r := range results {
+		tokens = append(tokens, r.Package.Type+":"+r.Package.Token)
+	}
+	return tokens
+}
+
+func TestRank(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		opts  Options
+		want  []string
+	}{
+		{
+			// exact name, then prefix, then substring, then fuzzy name, then description
+			name:  "name matches first",
+			query: "jq",
+			want:  []string{"cask:jq", "formula:jq", "formula:jqp", "formula:gojq", "formula:json-query"},
+		},
+		{
+			name:  "type option",
+			query: "jq",
+			opts:  Options{Type: "formula", Limit: 2},
+			want:  []string{"formula:jq", "formula:jqp"},
+		},
+		{
+			name:  "exact disables fuzzy matching",
+			query: "jsq",
+			opts:  Options{Exact: true},
+			want:  nil,
+		},
+		{
+			name:  "fuzzy name",
+			query: "jsq",
+			want:  []string{"formula:json-query"},
+		},
+		{
+			name:  "every term must match",
+			query: "json pager",
+			want:  []string{"formula:jless"},
+		},
+		{
+			name:  "full name",
+			query: "mozilla",
+			want:  []string{"cask:firefox"},
+		},
+		{
+			name:  "qualifiers and excluded terms",
+			query: "json type:formula -pager",
+			want:  []string{"formula:json-query", "formula:fx", "formula:jq"},
+		},
+		{
+			name:  "empty query matches everything by token",
+			query: "type:cask",
+			want:  []string{"cask:jq", "cask:firefox"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := rankTokens(Rank(testPackages, q, tt.opts))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Rank(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankScores(t *testing.T) {
+	q, _ := ParseQuery("jq")
+	results := Rank(testPackages, q, Options{Type: "formula"})
+	want := []int{scoreExactName, scoreNamePrefix, scoreNameSubstr, scoreFuzzyName}
+	for i, score := range want {
+		if results[i].Score != score {
+			t.Errorf("%s scored %d, want %d", results[i].Package.Token, results[i].Score, score)
+		}
+	}
+}
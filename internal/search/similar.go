@@ -0,0 +1,224 @@
+package search
+
+import (
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/user/go-brew-search/internal/api"
+)
+
+// How much each signal counts towards the similarity of two packages
+const (
+	similarDescWeight = 0.6
+	similarDepsWeight = 0.25
+	similarSiteWeight = 0.15
+)
+
+// minSimilarity leaves out packages that only share a common word or two
+const minSimilarity = 0.1
+
+// codeHosts serve many unrelated projects, so their homepages are compared
+// by owner as well, as in "github.com/BurntSushi"
+var codeHosts = map[string]bool{
+	"github.com":    true,
+	"gitlab.com":    true,
+	"codeberg.org":  true,
+	"bitbucket.org": true,
+	"git.sr.ht":     true,
+}
+
+// Similarity finds packages like a given one from the package index alone:
+// names and descriptions compared as TF-IDF vectors, shared dependencies
+// weighted by how rare they are, and the site of the homepage. Names count
+// because descriptions often mention related tools, as ripgrep's does "The
+// Silver Searcher".
+type Similarity struct {
+	packages []api.Package
+	byKey    map[string]int
+
+	desc []vector // names and descriptions, unit length, by package
+	deps []vector // unit length, by package
+	site []string
+
+	descDocs map[string][]int // packages by name or description term
+	depDocs  map[string][]int // packages by dependency
+	siteDocs map[string][]int // packages by homepage site
+}
+
+// vector is a sparse vector of term weights
+type vector map[string]float64
+
+// NewSimilarity prepares packages for comparison
+func NewSimilarity(packages []api.Package) *Similarity {
+	s := &Similarity{
+		packages: packages,
+		byKey:    make(map[string]int, len(packages)),
+		desc:     make([]vector, len(packages)),
+		deps:     make([]vector, len(packages)),
+		site:     make([]string, len(packages)),
+		descDocs: make(map[string][]int),
+		depDocs:  make(map[string][]int),
+		siteDocs: make(map[string][]int),
+	}
+
+	counts := make([]map[string]int, len(packages))
+	for i, pkg := range packages {
+		s.byKey[docKey(pkg)] = i
+
+		counts[i] = make(map[string]int)
+		for _, term := range Terms(describe(pkg)) {
+			if counts[i][term] == 0 {
+				s.descDocs[term] = append(s.descDocs[term], i)
+			}
+			counts[i][term]++
+		}
+		for _, dep := range uniqueStrings(pkg.Dependencies) {
+			s.depDocs[dep] = append(s.depDocs[dep], i)
+		}
+		if site := homepageSite(pkg.Homepage); site != "" {
+			s.site[i] = site
+			s.siteDocs[site] = append(s.siteDocs[site], i)
+		}
+	}
+
+	n := float64(len(packages))
+	for i, pkg := range packages {
+		v := make(vector, len(counts[i]))
+		for term, count := range counts[i] {
+			v[term] = float64(count) * math.Log(n/float64(len(s.descDocs[term])))
+		}
+		s.desc[i] = v.normalize()
+
+		v = make(vector, len(pkg.Dependencies))
+		for _, dep := range uniqueStrings(pkg.Dependencies) {
+			v[dep] = math.Log(n / float64(len(s.depDocs[dep])))
+		}
+		s.deps[i] = v.normalize()
+	}
+	return s
+}
+
+// Similar returns up to limit packages of the same type most like pkg, best
+// first. Scores range from 0 to 100.
+func (s *Similarity) Similar(pkg api.Package, limit int) []Result {
+	i, ok := s.byKey[docKey(pkg)]
+	if !ok {
+		return nil
+	}
+
+	candidates := make(map[int]bool)
+	for term := range s.desc[i] {
+		for _, j := range s.descDocs[term] {
+			candidates[j] = true
+		}
+	}
+	for dep := range s.deps[i] {
+		for _, j := range s.depDocs[dep] {
+			candidates[j] = true
+		}
+	}
+	if site := s.site[i]; site != "" {
+		for _, j := range s.siteDocs[site] {
+			candidates[j] = true
+		}
+	}
+	delete(candidates, i)
+
+	type scored struct {
+		doc   int
+		score float64
+	}
+	var results []scored
+	for j := range candidates {
+		if s.packages[j].Type != s.packages[i].Type {
+			continue
+		}
+		score := similarDescWeight*s.desc[i].dot(s.desc[j]) + similarDepsWeight*s.deps[i].dot(s.deps[j])
+		if s.site[i] != "" && s.site[i] == s.site[j] {
+			score += similarSiteWeight
+		}
+		if score >= minSimilarity {
+			results = append(results, scored{doc: j, score: score})
+		}
+	}
+
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].score != results[b].score {
+			return results[a].score > results[b].score
+		}
+		return s.packages[results[a].doc].Token < s.packages[results[b].doc].Token
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	similar := make([]Result, len(results))
+	for k, r := range results {
+		similar[k] = Result{Package: s.packages[r.doc], Score: int(math.Round(r.score * 100))}
+	}
+	return similar
+}
+
+// describe returns the text a package's TF-IDF vector is built from
+func describe(pkg api.Package) string {
+	return pkg.Token + " " + pkg.Description
+}
+
+func (v vector) normalize() vector {
+	var sum float64
+	for _, w := range v {
+		sum += w * w
+	}
+	if sum == 0 {
+		return v
+	}
+	norm := math.Sqrt(sum)
+	for term, w := range v {
+		v[term] = w / norm
+	}
+	return v
+}
+
+func (v vector) dot(other vector) float64 {
+	if len(other) < len(v) {
+		v, other = other, v
+	}
+	var sum float64
+	for term, w := range v {
+		sum += w * other[term]
+	}
+	return sum
+}
+
+// homepageSite returns the host of a homepage without "www.", plus the owner
+// on code hosts
+func homepageSite(homepage string) string {
+	u, err := url.Parse(homepage)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if !codeHosts[host] {
+		return host
+	}
+	owner, _, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+	if owner == "" {
+		return ""
+	}
+	return host + "/" + strings.ToLower(owner)
+}
+
+func uniqueStrings(list []string) []string {
+	seen := make(map[string]bool, len(list))
+	var unique []string
+	for _, s := range list {
+		if !seen[s] {
+			seen[s] = true
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/user/go-brew-search/internal/api"
+)
+
+var similarPackages = []api.Package{
+	{Token: "ripgrep", Type: "formula", Description: "Search tool like grep and The Silver Searcher",
+		Homepage: "https://github.com/BurntSushi/ripgrep", Dependencies: []string{"pcre2"}},
+	{Token: "ugrep", Type: "formula", Description: "Ultra fast grep with query UI, fuzzy search, archive search, and more",
+		Homepage: "https://github.com/Genivia/ugrep", Dependencies: []string{"pcre2", "xz", "zstd"}},
+	{Token: "the_silver_searcher", Type: "formula", Description: "Code-search similar to ack",
+		Homepage: "https://github.com/ggreer/the_silver_searcher", Dependencies: []string{"pcre", "xz"}},
+	{Token: "xsv", Type: "formula", Description: "Fast CSV command-line toolkit written in Rust",
+		Homepage: "https://github.com/BurntSushi/xsv"},
+	{Token: "git", Type: "formula", Description: "Distributed revision control system",
+		Homepage: "https://git-scm.com", Dependencies: []string{"gettext", "pcre2"}},
+	{Token: "jq", Type: "formula", Description: "Lightweight and flexible command-line JSON processor",
+		Homepage: "https://jqlang.github.io/jq/", Dependencies: []string{"oniguruma"}},
+	{Token: "wget", Type: "formula", Description: "Internet file retriever",
+		Homepage: "https://www.gnu.org/software/wget/", Dependencies: []string{"gettext", "libidn2", "openssl@3"}},
+	{Token: "fd", Type: "formula", Description: "Simple, fast and user-friendly alternative to find",
+		Homepage: "https://github.com/sharkdp/fd"},
+	{Token: "firefox", Type: "cask", Description: "Web browser",
+		Homepage: "https://www.mozilla.org/firefox/"},
+	{Token: "wget-gui", Type: "cask", Description: "Graphical front end for wget",
+		Homepage: "https://www.gnu.org/software/wget/"},
+}
+
+func similarTokens(results []Result) []string {
+	tokens := make([]string, len(results))
+	for i, r := range results {
+		tokens[i] = r.Package.Token
+	}
+	return tokens
+}
+
+func TestSimilar(t *testing.T) {
+	s := NewSimilarity(similarPackages)
+	results := s.Similar(similarPackages[0], 0)
+	got := similarTokens(results)
+
+	if len(got) < 2 || !(got[0] == "ugrep" && got[1] == "the_silver_searcher" || got[0] == "the_silver_searcher" && got[1] == "ugrep") {
+		t.Fatalf("Similar(ripgrep) = %q, want ugrep and the_silver_searcher first", got)
+	}
+
+	rank := make(map[string]int)
+	for i, token := range got {
+		rank[token] = i
+	}
+	if _, ok := rank["ripgrep"]; ok {
+		t.Error("ripgrep is similar to itself")
+	}
+
+	// xsv shares only the homepage owner and git only a dependency; both
+	// count, though for less than a shared description
+	for _, token := range []string{"xsv", "git"} {
+		if i, ok := rank[token]; !ok || i < 2 {
+			t.Errorf("%s at %d in %q, want it after the grep tools", token, i, got)
+		}
+	}
+
+	// Packages sharing nothing, or only a common word, fall below the cutoff
+	for _, token := range []string{"jq", "wget", "fd", "firefox"} {
+		if _, ok := rank[token]; ok {
+			t.Errorf("%s in %q, want unrelated packages left out", token, got)
+		}
+	}
+	for _, r := range results {
+		if r.Score < int(minSimilarity*100) || r.Score > 100 {
+			t.Errorf("%s scores %d, want %d to 100", r.Package.Token, r.Score, int(minSimilarity*100))
+		}
+	}
+
+	if limited := s.Similar(similarPackages[0], 1); len(limited) != 1 || limited[0].Package.Token != got[0] {
+		t.Errorf("Similar with limit 1 = %q, want %q", similarTokens(limited), got[:1])
+	}
+}
+
+func TestSimilarSameType(t *testing.T) {
+	s := NewSimilarity(similarPackages)
+
+	// wget-gui shares wget's name and homepage, but is a cask
+	for _, r := range s.Similar(similarPackages[6], 0) {
+		if r.Package.Type != "formula" {
+			t.Errorf("Similar(wget) includes %s %s", r.Package.Type, r.Package.Token)
+		}
+	}
+	for _, r := range s.Similar(similarPackages[9], 0) {
+		if r.Package.Type != "cask" {
+			t.Errorf("Similar(wget-gui) includes %s %s", r.Package.Type, r.Package.Token)
+		}
+	}
+}
+
+func TestSimilarUnknown(t *testing.T) {
+	s := NewSimilarity(similarPackages)
+	if results := s.Similar(api.Package{Token: "ripgrep", Type: "cask"}, 0); results != nil {
+		t.Errorf("Similar of a package not in the index = %q, want nothing", similarTokens(results))
+	}
+}
+
+func TestHomepageSite(t *testing.T) {
+	tests := []struct {
+		homepage, want string
+	}{
+		{"https://github.com/BurntSushi/ripgrep", "github.com/burntsushi"},
+		{"https://www.gnu.org/software/wget/", "gnu.org"},
+		{"https://jqlang.github.io/jq/", "jqlang.github.io"},
+		{"https://github.com", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := homepageSite(tt.homepage); got != tt.want {
+			t.Errorf("homepageSite(%q) = %q, want %q", tt.homepage, got, tt.want)
+		}
+	}
+}
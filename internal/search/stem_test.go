@@ -0,0 +1,55 @@
+package search
+
+import "testing"
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word, want string
+	}{
+		// Short, mixed and non-letter words are left alone
+		{"go", "go"},
+		{"js", "js"},
+		{"python3", "python3"},
+		{"Running", "Running"},
+
+		{"caresses", "caress"},
+		{"ponies", "poni"},
+		{"caress", "caress"},
+		{"cats", "cat"},
+		{"feed", "feed"},
+		{"agreed", "agre"},
+		{"plastered", "plaster"},
+		{"motoring", "motor"},
+		{"sing", "sing"},
+		{"conflated", "conflat"},
+		{"sized", "size"},
+		{"hopping", "hop"},
+		{"falling", "fall"},
+		{"filing", "file"},
+		{"happy", "happi"},
+		{"sky", "sky"},
+		{"relational", "relat"},
+		{"digitizer", "digit"},
+		{"hopefulness", "hope"},
+		{"electrical", "electr"},
+		{"replacement", "replac"},
+		{"adoption", "adopt"},
+		{"effective", "effect"},
+		{"controll", "control"},
+		{"generalizations", "gener"},
+		{"databases", "databas"},
+	}
+	for _, tt := range tests {
+		if got := stem(tt.word); got != tt.want {
+			t.Errorf("stem(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestStemForms(t *testing.T) {
+	for _, word := range []string{"converting", "converter", "converts", "converted"} {
+		if got := stem(word); got != "convert" {
+			t.Errorf("stem(%q) = %q, want convert", word, got)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package text
+
+// Column describes a column for Fit
+type Column struct {
+	Natural int // width of its widest content
+	Min     int // narrowest it may get before the layout overflows
+	Max     int // widest it may get; 0 means no limit
+}
+
+// Fit divides width between columns separated by gaps of sep columns. Each
+// column gets its natural width, capped at Max. While that doesn't fit, the
+// widest column that is still above its minimum gives up a column, so a
+// long description shrinks before the names beside it do.
+func Fit(width, sep int, columns []Column) []int {
+	widths := make([]int, len(columns))
+	total := sep * max(0, len(columns)-1)
+	for i, c := range columns {
+		widths[i] = c.Natural
+		if c.Max > 0 {
+			widths[i] = min(widths[i], c.Max)
+		}
+		total += widths[i]
+	}
+
+	for total > width {
+		widest := -1
+		for i, c := range columns {
+			if widths[i] > c.Min && (widest == -1 || widths[i] > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest == -1 {
+			break // every column is at its minimum
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
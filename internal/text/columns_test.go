@@ -0,0 +1,50 @@
+package text
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFit(t *testing.T) {
+	// name, version and description columns, as in the package list
+	columns := []Column{
+		{Natural: 20, Min: 10, Max: 30},
+		{Natural: 8, Min: 8},
+		{Natural: 60, Min: 12},
+	}
+
+	tests := []struct {
+		name  string
+		width int
+		want  []int
+	}{
+		{"wide terminal", 120, []int{20, 8, 60}},
+		{"exact fit", 92, []int{20, 8, 60}},
+		{"description shrinks first", 80, []int{20, 8, 48}},
+		{"description down to the name", 52, []int{20, 8, 20}},
+		{"then both in turn", 46, []int{17, 8, 17}},
+		{"description at its minimum", 36, []int{12, 8, 12}},
+		{"then only the name", 35, []int{11, 8, 12}},
+		{"overflows at the minimums", 30, []int{10, 8, 12}},
+		{"zero width", 0, []int{10, 8, 12}},
+		{"negative width", -5, []int{10, 8, 12}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Fit(tt.width, 2, columns); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fit(%d) = %v, want %v", tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFitMax(t *testing.T) {
+	got := Fit(200, 1, []Column{{Natural: 50, Max: 30}, {Natural: 10}})
+	if want := []int{30, 10}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Fit = %v, want %v, capped at Max", got, want)
+	}
+
+	if got := Fit(80, 1, nil); len(got) != 0 {
+		t.Errorf("Fit with no columns = %v", got)
+	}
+}
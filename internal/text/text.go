@@ -0,0 +1,86 @@
+// Package text lays out text by display width rather than bytes, so
+// multi-byte descriptions are never cut mid-character and columns stay
+// aligned around CJK characters and emoji.
+package text
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// ellipsis marks text that was cut short
+const ellipsis = "..."
+
+// Width returns the number of terminal columns s takes
+func Width(s string) int {
+	return runewidth.StringWidth(s)
+}
+
+// Truncate shortens s to at most width columns, ending it with "..." if
+// anything was cut
+func Truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if Width(s) <= width {
+		return s
+	}
+	if width <= len(ellipsis) {
+		return runewidth.Truncate(s, width, "")
+	}
+	return runewidth.Truncate(s, width, ellipsis)
+}
+
+// Pad truncates s to width columns and fills it with spaces to exactly that
+// width
+func Pad(s string, width int) string {
+	s = Truncate(s, width)
+	return s + strings.Repeat(" ", max(0, width-Width(s)))
+}
+
+// Wrap breaks text into lines of at most width columns at spaces. Words
+// wider than a line, such as URLs or text without spaces, are split.
+func Wrap(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+	flush := func() {
+		lines = append(lines, line.String())
+		line.Reset()
+		lineWidth = 0
+	}
+
+	for _, word := range strings.Fields(text) {
+		wordWidth := Width(word)
+		if lineWidth > 0 && lineWidth+1+wordWidth > width {
+			flush()
+		}
+		if lineWidth > 0 {
+			line.WriteByte(' ')
+			lineWidth++
+		}
+
+		// Only a word wider than a whole line gets here at its start
+		for wordWidth > width {
+			head := runewidth.Truncate(word, width, "")
+			if head == "" {
+				break // not even one character fits
+			}
+			line.WriteString(head)
+			flush()
+			word = word[len(head):]
+			wordWidth = Width(word)
+		}
+		line.WriteString(word)
+		lineWidth += wordWidth
+	}
+	if lineWidth > 0 {
+		flush()
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,165 @@
+package text
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"ripgrep", 7},
+		{"日本語", 6},
+		{"🍺 beer", 7},
+		{"café", 4}, // combining acute accent
+		{"Ａ", 2},     // fullwidth letter
+	}
+	for _, tt := range tests {
+		if got := Width(tt.s); got != tt.want {
+			t.Errorf("Width(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"ripgrep", 10, "ripgrep"},
+		{"ripgrep", 7, "ripgrep"},
+		{"ripgrep", 6, "rip..."},
+		{"ripgrep", 3, "rip"},
+		{"ripgrep", 1, "r"},
+		{"ripgrep", 0, ""},
+		{"ripgrep", -1, ""},
+		{"日本語のテキスト", 7, "日本..."},
+		{"日本語のテキスト", 6, "日..."}, // a wide character never straddles the cut
+		{"日本語", 3, "日"},
+		{"日本語", 1, ""},
+		{"🍺🍺🍺", 5, "🍺..."},
+		{"café au lait", 7, "café..."}, // the accent stays on its letter
+	}
+	for _, tt := range tests {
+		got := Truncate(tt.s, tt.width)
+		if got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+		if tt.width > 0 && Width(got) > tt.width {
+			t.Errorf("Truncate(%q, %d) is %d columns wide", tt.s, tt.width, Width(got))
+		}
+	}
+}
+
+func TestPad(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"jq", 4, "jq  "},
+		{"ripgrep", 6, "rip..."},
+		{"日本", 5, "日本 "},
+		{"日本語", 3, "日 "}, // the cut wide character leaves a column to fill
+		{"🍺", 1, " "},    // nothing fits, so only padding
+		{"jq", 0, ""},
+		{"jq", -2, ""},
+	}
+	for _, tt := range tests {
+		got := Pad(tt.s, tt.width)
+		if got != tt.want {
+			t.Errorf("Pad(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+		if tt.width >= 0 && Width(got) != tt.width {
+			t.Errorf("Pad(%q, %d) is %d columns wide", tt.s, tt.width, Width(got))
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{
+			name:  "fits",
+			text:  "JSON processor",
+			width: 20,
+			want:  []string{"JSON processor"},
+		},
+		{
+			name:  "at spaces",
+			text:  "Search tool like grep and The Silver Searcher",
+			width: 16,
+			want:  []string{"Search tool like", "grep and The", "Silver Searcher"},
+		},
+		{
+			name:  "collapses whitespace",
+			text:  "  fast \n\t grep  ",
+			width: 20,
+			want:  []string{"fast grep"},
+		},
+		{
+			name:  "splits long words",
+			text:  "see https://github.com/BurntSushi/ripgrep",
+			width: 12,
+			want:  []string{"see", "https://gith", "ub.com/Burnt", "Sushi/ripgre", "p"},
+		},
+		{
+			name:  "wide characters",
+			text:  "日本語のテキスト です",
+			width: 5,
+			want:  []string{"日本", "語の", "テキ", "スト", "です"},
+		},
+		{
+			name:  "emoji",
+			text:  "🍺 Homebrew 🍺",
+			width: 11,
+			want:  []string{"🍺 Homebrew", "🍺"},
+		},
+		{
+			name:  "combining marks",
+			text:  "café café",
+			width: 4,
+			want:  []string{"café", "café"},
+		},
+		{
+			name:  "wider than a line",
+			text:  "日本",
+			width: 1,
+			want:  []string{"日本"}, // not even one character fits
+		},
+		{
+			name:  "zero width",
+			text:  "left  alone",
+			width: 0,
+			want:  []string{"left  alone"},
+		},
+		{
+			name:  "negative width",
+			text:  "left alone",
+			width: -1,
+			want:  []string{"left alone"},
+		},
+		{
+			name:  "empty",
+			text:  "",
+			width: 10,
+			want:  []string{""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Wrap(tt.text, tt.width)
+			if want := strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("Wrap(%q, %d) =\n%s\nwant\n%s", tt.text, tt.width, got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,859 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/search"
+	"github.com/user/go-brew-search/internal/text"
+)
+
+// finderMode is what confirming the selection means
+type finderMode int
+
+const (
+	modeSelect  finderMode = iota // ENTER without marks picks the package under the cursor
+	modeExclude                   // marked packages are skipped; ESC cancels
+)
+
+// tab is a predefined part of the list
+type tab struct {
+	name  string
+	match func(pkg api.Package, existing map[string]bool) bool
+}
+
+var tabs = []tab{
+	{"All", func(api.Package, map[string]bool) bool { return true }},
+	{"Formulae", func(pkg api.Package, _ map[string]bool) bool { return pkg.Type == "formula" }},
+	{"Casks", func(pkg api.Package, _ map[string]bool) bool { return pkg.Type == "cask" }},
+	{"In Brewfile", func(pkg api.Package, existing map[string]bool) bool { return existing[pkg.Token] }},
+}
+
+// Layout limits, in columns
+const (
+	minListWidth    = 30
+	minPreviewWidth = 20
+	previewStep     = 5
+)
+
+// similarListLimit is how many similar packages the similar action lists
+const similarListLimit = 50
+
+// similarPreviewLimit is how many similar packages the preview names
+const similarPreviewLimit = 5
+
+// view is a list the finder shows. The similar action pushes one; cancel
+// goes back to the one before, where the user left it.
+type view struct {
+	packages []api.Package
+	columns  []text.Column // of packages, for the list's layout
+	filter   string        // shown in the header
+
+	query  []rune
+	tab    int
+	cursor int
+}
+
+func newView(packages []api.Package, filter string) view {
+	return view{packages: packages, columns: listColumns(packages), filter: filter}
+}
+
+// finder is the interactive package selector
+type finder struct {
+	screen   tcell.Screen
+	opts     Options
+	theme    theme
+	keys     keyMap
+	mode     finderMode
+	existing map[string]bool
+
+	views []view
+	tab   int
+
+	hideDeprecated bool
+	hideListed     bool
+	selectedOnly   bool
+
+	query     []rune
+	queryPos  int // in runes
+	lastQuery search.Query
+
+	matched   []api.Package // the current tab, after query and toggles
+	tabCounts []int
+	cursor    int
+	offset    int
+
+	marked   map[string]api.Package // by key
+	markSeq  map[string]int         // order of marking, by key
+	nextMark int
+
+	showPreview   bool
+	previewWidth  int // 0 means half the screen
+	previewScroll int
+
+	showHelp bool
+	message  string
+
+	loading bool  // packages are still being added
+	loadErr error // why loading failed, shown until the finder closes
+}
+
+// packagesEvent brings packages the loader added to the event loop
+type packagesEvent struct {
+	tcell.EventTime
+	packages []api.Package
+}
+
+// loadedEvent tells the event loop the loader has finished
+type loadedEvent struct {
+	tcell.EventTime
+	err error
+}
+
+// similarReadyEvent redraws the preview once similar packages are ready
+type similarReadyEvent struct {
+	tcell.EventTime
+}
+
+func newFinder(packages []api.Package, existing map[string]bool, opts Options, mode finderMode) (*finder, error) {
+	keys, err := newKeyMap(opts.Keys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key bindings: %w", err)
+	}
+
+	f := &finder{
+		opts:        opts,
+		theme:       themeFor(opts.Theme),
+		keys:        keys,
+		mode:        mode,
+		existing:    existing,
+		views:       []view{newView(sortPackages(packages, opts), opts.Filter)},
+		query:       []rune(opts.Query),
+		marked:      make(map[string]api.Package),
+		markSeq:     make(map[string]int),
+		showPreview: true,
+	}
+	f.queryPos = len(f.query)
+	f.refresh()
+	return f, nil
+}
+
+// run shows the finder until the user confirms or cancels. ok is false when
+// they cancelled.
+func (f *finder) run() (selected []api.Package, ok bool, err error) {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open the terminal: %w", err)
+	}
+	if err := screen.Init(); err != nil {
+		return nil, false, fmt.Errorf("failed to initialize the terminal: %w", err)
+	}
+	defer screen.Fini()
+	f.screen = screen
+
+	if f.opts.Load != nil {
+		f.loading = true
+		go f.load(screen)
+	}
+	if f.opts.Similar != nil && f.opts.SimilarReady != nil {
+		go f.waitSimilar(screen)
+	}
+
+	for {
+		f.draw()
+		switch ev := screen.PollEvent().(type) {
+		case *tcell.EventResize:
+			screen.Sync()
+		case *packagesEvent:
+			f.addPackages(ev.packages)
+		case *loadedEvent:
+			f.loading, f.loadErr = false, ev.err
+		case *tcell.EventKey:
+			if done, ok := f.handleKey(ev); done {
+				if !ok {
+					return nil, false, nil
+				}
+				return f.selection(), true, nil
+			}
+		}
+	}
+}
+
+// load runs the loader, passing what it adds to the event loop. Events
+// posted after the finder has closed are dropped.
+func (f *finder) load(screen tcell.Screen) {
+	err := f.opts.Load(func(packages []api.Package) {
+		ev := &packagesEvent{packages: packages}
+		ev.SetEventNow()
+		screen.PostEvent(ev)
+	})
+	ev := &loadedEvent{err: err}
+	ev.SetEventNow()
+	screen.PostEvent(ev)
+}
+
+// waitSimilar tells the event loop when similar packages are ready
+func (f *finder) waitSimilar(screen tcell.Screen) {
+	<-f.opts.SimilarReady
+	ev := &similarReadyEvent{}
+	ev.SetEventNow()
+	screen.PostEvent(ev)
+}
+
+// addPackages adds loaded packages to the main list, keeping the cursor on
+// the package the user moved it to
+func (f *finder) addPackages(packages []api.Package) {
+	if len(packages) == 0 {
+		return
+	}
+	base := &f.views[0]
+	base.packages = sortPackages(append(base.packages, packages...), f.opts)
+	base.columns = listColumns(base.packages)
+	if len(f.views) > 1 {
+		return
+	}
+
+	// A cursor left at the top stays there as packages arrive
+	current, ok := f.current()
+	f.refresh()
+	if !ok || f.cursor == 0 {
+		return
+	}
+	for i, pkg := range f.matched {
+		if packageKey(pkg) == packageKey(current) {
+			f.cursor = i
+			return
+		}
+	}
+}
+
+// selection is what confirming returns: the marked packages in the order
+// they were marked or, when selecting, the package under the cursor
+func (f *finder) selection() []api.Package {
+	if len(f.marked) > 0 || f.mode == modeExclude {
+		return f.markedPackages()
+	}
+	if pkg, ok := f.current(); ok {
+		return []api.Package{pkg}
+	}
+	return nil
+}
+
+// targets are the packages an action applies to: the marked ones, or the
+// one under the cursor
+func (f *finder) targets() []api.Package {
+	if len(f.marked) > 0 {
+		return f.markedPackages()
+	}
+	if pkg, ok := f.current(); ok {
+		return []api.Package{pkg}
+	}
+	return nil
+}
+
+func (f *finder) markedPackages() []api.Package {
+	packages := make([]api.Package, 0, len(f.marked))
+	for _, pkg := range f.marked {
+		packages = append(packages, pkg)
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return f.markSeq[packageKey(packages[i])] < f.markSeq[packageKey(packages[j])]
+	})
+	return packages
+}
+
+func (f *finder) current() (api.Package, bool) {
+	if f.cursor < 0 || f.cursor >= len(f.matched) {
+		return api.Package{}, false
+	}
+	return f.matched[f.cursor], true
+}
+
+func packageKey(pkg api.Package) string {
+	return pkg.Type + ":" + pkg.Token
+}
+
+// refresh matches the current view against the query, toggles and tab
+func (f *finder) refresh() {
+	q, err := search.ParseQuery(string(f.query))
+	if err != nil {
+		// Keep the last valid query while one is being typed, such as an
+		// unterminated quote
+		q = f.lastQuery
+	}
+	f.lastQuery = q
+
+	packages := f.views[len(f.views)-1].packages
+	if len(q.Terms) > 0 {
+		results := search.Rank(packages, q, search.Options{Existing: f.existing})
+		packages = make([]api.Package, len(results))
+		for i, r := range results {
+			packages[i] = r.Package
+		}
+	} else {
+		packages = q.Filter(packages, f.existing)
+	}
+
+	f.matched = f.matched[:0]
+	f.tabCounts = make([]int, len(tabs))
+	for _, pkg := range packages {
+		if f.hideDeprecated && pkg.Deprecated || f.hideListed && f.existing[pkg.Token] {
+			continue
+		}
+		if _, marked := f.marked[packageKey(pkg)]; f.selectedOnly && !marked {
+			continue
+		}
+		for i, t := range tabs {
+			if t.match(pkg, f.existing) {
+				f.tabCounts[i]++
+			}
+		}
+		if tabs[f.tab].match(pkg, f.existing) {
+			f.matched = append(f.matched, pkg)
+		}
+	}
+
+	f.cursor = max(0, min(f.cursor, len(f.matched)-1))
+	f.previewScroll = 0
+}
+
+// handleKey reacts to a key press. done is true when the finder should
+// close, and ok whether the selection was confirmed.
+func (f *finder) handleKey(ev *tcell.EventKey) (done, ok bool) {
+	f.message = ""
+	if ev.Key() == tcell.KeyCtrlC {
+		return true, false
+	}
+	if action, bound := f.keys.actions[eventKey(ev)]; bound && f.available(action) {
+		return f.do(action)
+	}
+	if f.showHelp {
+		f.showHelp = false
+		return false, false
+	}
+
+	query := string(f.query)
+	switch ev.Key() {
+	case tcell.KeyUp, tcell.KeyCtrlP, tcell.KeyCtrlK:
+		f.move(-1)
+	case tcell.KeyDown, tcell.KeyCtrlN, tcell.KeyCtrlJ:
+		f.move(1)
+	case tcell.KeyPgUp:
+		f.move(-f.listHeight())
+	case tcell.KeyPgDn:
+		f.move(f.listHeight())
+	case tcell.KeyLeft, tcell.KeyCtrlB:
+		f.queryPos = max(0, f.queryPos-1)
+	case tcell.KeyRight, tcell.KeyCtrlF:
+		f.queryPos = min(len(f.query), f.queryPos+1)
+	case tcell.KeyHome, tcell.KeyCtrlA:
+		f.queryPos = 0
+	case tcell.KeyEnd, tcell.KeyCtrlE:
+		f.queryPos = len(f.query)
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if f.queryPos > 0 {
+			f.query = append(f.query[:f.queryPos-1], f.query[f.queryPos:]...)
+			f.queryPos--
+		}
+	case tcell.KeyDelete:
+		if f.queryPos < len(f.query) {
+			f.query = append(f.query[:f.queryPos], f.query[f.queryPos+1:]...)
+		}
+	case tcell.KeyCtrlW:
+		start := f.queryPos
+		for start > 0 && f.query[start-1] == ' ' {
+			start--
+		}
+		for start > 0 && f.query[start-1] != ' ' {
+			start--
+		}
+		f.query = append(f.query[:start], f.query[f.queryPos:]...)
+		f.queryPos = start
+	case tcell.KeyCtrlU:
+		f.query = f.query[f.queryPos:]
+		f.queryPos = 0
+	case tcell.KeyRune:
+		if ev.Modifiers()&tcell.ModAlt == 0 {
+			f.query = append(f.query[:f.queryPos], append([]rune{ev.Rune()}, f.query[f.queryPos:]...)...)
+			f.queryPos++
+		}
+	}
+
+	if string(f.query) != query {
+		f.cursor, f.offset = 0, 0
+		f.refresh()
+	}
+	return false, false
+}
+
+// available reports whether an action can be used in this finder
+func (f *finder) available(action string) bool {
+	switch action {
+	case ActionAdd:
+		return f.opts.Actions.Add != nil
+	case ActionRemove:
+		return f.opts.Actions.Remove != nil
+	case ActionInstall:
+		return f.opts.Actions.Install != nil
+	case ActionSimilar:
+		return f.opts.Similar != nil
+	}
+	return true
+}
+
+// do runs a bound action
+func (f *finder) do(action string) (done, ok bool) {
+	switch action {
+	case ActionToggle:
+		if pkg, ok := f.current(); ok {
+			f.toggleMark(pkg)
+			f.move(1)
+		}
+	case ActionConfirm:
+		return true, true
+	case ActionCancel:
+		if len(f.views) > 1 {
+			f.popView()
+			return false, false
+		}
+		return true, false
+	case ActionNextTab, ActionPrevTab:
+		step := 1
+		if action == ActionPrevTab {
+			step = len(tabs) - 1
+		}
+		f.tab = (f.tab + step) % len(tabs)
+		f.cursor, f.offset = 0, 0
+		f.refresh()
+	case ActionHideDeprecated:
+		f.hideDeprecated = !f.hideDeprecated
+		f.refresh()
+	case ActionHideListed:
+		f.hideListed = !f.hideListed
+		f.refresh()
+	case ActionSelectedOnly:
+		f.selectedOnly = !f.selectedOnly
+		f.cursor, f.offset = 0, 0
+		f.refresh()
+	case ActionPreview:
+		f.showPreview = !f.showPreview
+	case ActionPreviewWider, ActionPreviewNarrower:
+		w, _ := f.screen.Size()
+		step := previewStep
+		if action == ActionPreviewNarrower {
+			step = -step
+		}
+		f.previewWidth = f.previewColumns(w) + step
+		f.showPreview = true
+	case ActionPreviewUp:
+		f.previewScroll = max(0, f.previewScroll-1)
+	case ActionPreviewDown:
+		f.previewScroll++
+	case ActionOpenHomepage:
+		f.openHomepage()
+	case ActionCopyInstall:
+		f.copyInstall()
+	case ActionAdd, ActionRemove:
+		f.changeBrewfile(action)
+	case ActionInstall:
+		f.install()
+	case ActionSimilar:
+		f.similar()
+	case ActionHelp:
+		f.showHelp = !f.showHelp
+	}
+	return false, false
+}
+
+func (f *finder) move(delta int) {
+	f.cursor = max(0, min(f.cursor+delta, len(f.matched)-1))
+	f.previewScroll = 0
+}
+
+func (f *finder) toggleMark(pkg api.Package) {
+	key := packageKey(pkg)
+	if _, ok := f.marked[key]; ok {
+		delete(f.marked, key)
+		delete(f.markSeq, key)
+		return
+	}
+	f.marked[key] = pkg
+	f.markSeq[key] = f.nextMark
+	f.nextMark++
+}
+
+func (f *finder) unmark(packages []api.Package) {
+	for _, pkg := range packages {
+		delete(f.marked, packageKey(pkg))
+		delete(f.markSeq, packageKey(pkg))
+	}
+}
+
+func (f *finder) openHomepage() {
+	pkg, ok := f.current()
+	if !ok {
+		return
+	}
+	if pkg.Homepage == "" {
+		f.message = f.theme.label("⚠️ ", pkg.Token+" has no homepage")
+		return
+	}
+	if err := openURL(pkg.Homepage); err != nil {
+		f.message = f.theme.label("❌", "Failed to open the homepage: "+err.Error())
+		return
+	}
+	f.message = f.theme.label("🌐", "Opened "+pkg.Homepage)
+}
+
+func (f *finder) copyInstall() {
+	targets := f.targets()
+	if len(targets) == 0 {
+		return
+	}
+	commands := make([]string, len(targets))
+	for i, pkg := range targets {
+		commands[i] = pkg.InstallCommand()
+	}
+	if err := copyText(strings.Join(commands, "\n")); err != nil {
+		f.message = f.theme.label("❌", "Failed to copy: "+err.Error())
+		return
+	}
+	f.message = f.theme.label("📋", "Copied "+strings.Join(commands, "; "))
+}
+
+// changeBrewfile adds the targets to the Brewfile or removes them
+func (f *finder) changeBrewfile(action string) {
+	targets := f.targets()
+	if len(targets) == 0 {
+		return
+	}
+
+	change, listed := f.opts.Actions.Add, true
+	if action == ActionRemove {
+		change, listed = f.opts.Actions.Remove, false
+	}
+	message, err := change(targets)
+	if err != nil {
+		f.message = f.theme.label("❌", err.Error())
+		return
+	}
+
+	for _, pkg := range targets {
+		f.existing[pkg.Token] = listed
+	}
+	f.unmark(targets)
+	f.message = message
+	f.refresh()
+}
+
+// install runs the install action with the screen suspended, so brew's
+// output shows, and waits for ENTER before returning to the list
+func (f *finder) install() {
+	targets := f.targets()
+	if len(targets) == 0 {
+		return
+	}
+	if err := f.screen.Suspend(); err != nil {
+		f.message = f.theme.label("❌", err.Error())
+		return
+	}
+
+	err := f.opts.Actions.Install(targets)
+	if err != nil {
+		fmt.Println("❌", err)
+	}
+	fmt.Print("\nPress ENTER to return to the list ")
+	bufio.NewReader(os.Stdin).ReadString('\n')
+
+	if err := f.screen.Resume(); err != nil {
+		f.message = f.theme.label("❌", err.Error())
+		return
+	}
+	if err != nil {
+		f.message = f.theme.label("❌", err.Error())
+		return
+	}
+	f.unmark(targets)
+	f.message = f.theme.label("✨", fmt.Sprintf("Installed %d packages", len(targets)))
+}
+
+// similar lists the package under the cursor and the packages most like it
+func (f *finder) similar() {
+	pkg, ok := f.current()
+	if !ok {
+		return
+	}
+	if !f.opts.similarReady() {
+		f.message = f.theme.label("⏳", "Still looking for similar packages, try again in a moment")
+		return
+	}
+	similar := f.opts.Similar(pkg, similarListLimit)
+	if len(similar) == 0 {
+		f.message = f.theme.label("🧭", "No similar packages found for "+pkg.Token)
+		return
+	}
+
+	top := &f.views[len(f.views)-1]
+	top.query, top.tab, top.cursor = f.query, f.tab, f.cursor
+	f.views = append(f.views, newView(append([]api.Package{pkg}, similar...), "similar to "+pkg.Token))
+
+	f.query, f.queryPos, f.tab = nil, 0, 0
+	f.cursor, f.offset = 0, 0
+	f.refresh()
+}
+
+func (f *finder) popView() {
+	f.views = f.views[:len(f.views)-1]
+	top := f.views[len(f.views)-1]
+	f.query, f.queryPos, f.tab = top.query, len(top.query), top.tab
+	f.cursor, f.offset = top.cursor, 0
+	f.refresh()
+}
+
+// Screen layout: the tabs and filter, the prompt, an information line and a rule,
+// then the list and preview, and the status bar at the bottom
+const (
+	rowTabs   = 0
+	rowPrompt = 1
+	rowInfo   = 2
+	rowRule   = 3
+	listTop   = 4
+)
+
+func (f *finder) listHeight() int {
+	_, h := f.screen.Size()
+	return max(1, h-listTop-1)
+}
+
+// previewColumns returns the width of the preview, or 0 when it is hidden or
+// doesn't fit
+func (f *finder) previewColumns(w int) int {
+	if !f.showPreview || w-minListWidth-1 < minPreviewWidth {
+		return 0
+	}
+	width := f.previewWidth
+	if width == 0 {
+		width = w / 2
+	}
+	return max(minPreviewWidth, min(width, w-minListWidth-1))
+}
+
+func (f *finder) draw() {
+	s := f.screen
+	s.Clear()
+	w, h := s.Size()
+	t := f.theme
+	normal := tcell.StyleDefault
+	dim := normal.Dim(true)
+
+	// Tabs
+	x := 1
+	for i, tb := range tabs {
+		style := normal
+		if i == f.tab {
+			style = style.Reverse(true).Bold(true)
+		}
+		x = f.drawText(x, rowTabs, w, fmt.Sprintf(" %s (%d) ", tb.name, f.tabCounts[i]), style) + 1
+	}
+	if filter := f.views[len(f.views)-1].filter; filter != "" {
+		f.drawText(x+2, rowTabs, w, t.label("🔎", "Filter: "+filter), dim)
+	}
+
+	// Prompt
+	x = f.drawText(1, rowPrompt, w, t.label("🔍", "Search packages: "), normal.Bold(true))
+	f.drawText(x, rowPrompt, w, string(f.query), normal)
+	s.ShowCursor(x+text.Width(string(f.query[:f.queryPos])), rowPrompt)
+
+	// Information line and rule
+	info := []string{t.legend(), f.hint()}
+	if f.loading {
+		info = append([]string{t.label("⏳", "Loading packages...")}, info...)
+	}
+	if age := t.dataAge(f.opts); age != "" {
+		info = append(info, age)
+	}
+	f.drawText(1, rowInfo, w, strings.Join(info, "   ·   "), dim)
+	f.drawText(0, rowRule, w, strings.Repeat("─", w), dim)
+
+	// List
+	pw := f.previewColumns(w)
+	listW := w
+	if pw > 0 {
+		listW = w - pw - 1
+	}
+	height := f.listHeight()
+	if f.cursor < f.offset {
+		f.offset = f.cursor
+	}
+	if f.cursor >= f.offset+height {
+		f.offset = f.cursor - height + 1
+	}
+	// The pointer, mark and markers come before the columns, and a space
+	// after them keeps the description off the preview
+	prefix := 4 + 2*(t.iconWidth+1)
+	widths := text.Fit(listW-prefix-1, text.Width(columnSep), f.views[len(f.views)-1].columns)
+	for row := 0; row < height && f.offset+row < len(f.matched); row++ {
+		i := f.offset + row
+		pkg := f.matched[i]
+
+		style, pointer := normal, "  "
+		if i == f.cursor {
+			style, pointer = normal.Reverse(true), "> "
+		}
+		mark := " "
+		if _, ok := f.marked[packageKey(pkg)]; ok {
+			mark = t.marked(f.mode == modeExclude)
+		}
+
+		end := f.drawText(0, listTop+row, listW, pointer+mark+" "+displayLine(pkg, f.existing, t, widths), style)
+		for ; end < listW; end++ {
+			s.SetContent(end, listTop+row, ' ', nil, style)
+		}
+	}
+	if len(f.matched) == 0 {
+		empty := "No matching packages"
+		if f.loading {
+			empty = "Loading packages..."
+		}
+		f.drawText(2, listTop, listW, empty, dim)
+	}
+
+	// Preview
+	if pw > 0 {
+		for row := 0; row < height; row++ {
+			s.SetContent(listW, listTop+row, '│', nil, dim)
+		}
+		if pkg, ok := f.current(); ok {
+			lines := strings.Split(PackageDetails(pkg, f.existing[pkg.Token], pw-2, f.opts), "\n")
+			f.previewScroll = min(f.previewScroll, max(0, len(lines)-height))
+			for row := 0; row < height && f.previewScroll+row < len(lines); row++ {
+				f.drawText(listW+2, listTop+row, w, lines[f.previewScroll+row], normal)
+			}
+		}
+	}
+
+	if f.showHelp {
+		f.drawHelp(listW, height)
+	}
+
+	f.drawStatus(w, h-1)
+	s.Show()
+}
+
+// hint explains the keys for selecting and confirming
+func (f *finder) hint() string {
+	toggle := "Select"
+	if f.mode == modeExclude {
+		toggle = "Mark to skip"
+	}
+	parts := []string{
+		f.keys.label(ActionToggle) + ": " + toggle,
+		f.keys.label(ActionConfirm) + ": Confirm",
+		f.keys.label(ActionCancel) + ": Cancel",
+	}
+	if help := f.keys.label(ActionHelp); help != "" {
+		parts = append(parts, help+": Keys")
+	}
+	return strings.Join(parts, "   ")
+}
+
+func (f *finder) drawStatus(w, row int) {
+	style := tcell.StyleDefault.Reverse(true)
+	for x := 0; x < w; x++ {
+		f.screen.SetContent(x, row, ' ', nil, style)
+	}
+
+	total := len(f.views[len(f.views)-1].packages)
+	parts := []string{fmt.Sprintf("%d of %d", len(f.matched), total)}
+	if len(f.marked) > 0 {
+		verb := "selected"
+		if f.mode == modeExclude {
+			verb = "marked to skip"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", len(f.marked), verb))
+	}
+	if f.hideDeprecated {
+		parts = append(parts, "hiding deprecated")
+	}
+	if f.hideListed {
+		parts = append(parts, "hiding Brewfile packages")
+	}
+	if f.selectedOnly {
+		parts = append(parts, "selected only")
+	}
+	if len(f.views) > 1 {
+		parts = append(parts, f.keys.label(ActionCancel)+": back")
+	}
+	x := f.drawText(1, row, w, strings.Join(parts, " · "), style)
+
+	message := f.message
+	if message == "" && f.loadErr != nil {
+		message = f.theme.label("❌", f.loadErr.Error())
+	}
+	if message != "" {
+		start := max(x+3, w-text.Width(message)-1)
+		f.drawText(start, row, w, message, style)
+	}
+}
+
+func (f *finder) drawHelp(width, height int) {
+	lines := f.keys.helpLines(f.available)
+	boxW := 0
+	for _, line := range lines {
+		boxW = max(boxW, text.Width(line))
+	}
+	boxW = min(boxW+4, width)
+	boxH := min(len(lines)+2, height)
+
+	left := max(0, (width-boxW)/2)
+	top := listTop + max(0, (height-boxH)/2)
+	style := tcell.StyleDefault.Reverse(true)
+	for row := 0; row < boxH; row++ {
+		for x := left; x < left+boxW; x++ {
+			f.screen.SetContent(x, top+row, ' ', nil, style)
+		}
+	}
+	for i := 0; i < boxH-2; i++ {
+		f.drawText(left+2, top+1+i, left+boxW-1, lines[i], style)
+	}
+}
+
+// drawText draws s from column x of row y, up to column maxX, and returns
+// the column after it. Zero-width runes such as accents join the character
+// before them. The emoji variation selector is dropped: tcell sizes a cell by
+// its base rune, so "🖥️" would take two columns on the terminal but one on
+// the screen, and shift the rest of the row.
+func (f *finder) drawText(x, y, maxX int, s string, style tcell.Style) int {
+	prevX, prev := -1, rune(0)
+	var combining []rune
+	for _, r := range s {
+		if r == emojiPresentation {
+			continue
+		}
+		width := runewidth.RuneWidth(r)
+		if width == 0 {
+			if prevX >= 0 {
+				combining = append(combining, r)
+				f.screen.SetContent(prevX, y, prev, combining, style)
+			}
+			continue
+		}
+		if x+width > maxX {
+			break
+		}
+		f.screen.SetContent(x, y, r, nil, style)
+		prevX, prev, combining = x, r, nil
+		x += width
+	}
+	return x
+}
+
+// emojiPresentation is the variation selector that asks for a character to
+// be shown as an emoji
+const emojiPresentation = '\uFE0F'
@@ -0,0 +1,218 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Actions the selector's keys can be bound to, in the order the help lists
+// them
+const (
+	ActionToggle          = "toggle"
+	ActionConfirm         = "confirm"
+	ActionCancel          = "cancel"
+	ActionNextTab         = "next-tab"
+	ActionPrevTab         = "prev-tab"
+	ActionHideDeprecated  = "hide-deprecated"
+	ActionHideListed      = "hide-listed"
+	ActionSelectedOnly    = "selected-only"
+	ActionPreview         = "preview"
+	ActionPreviewWider    = "preview-wider"
+	ActionPreviewNarrower = "preview-narrower"
+	ActionPreviewUp       = "preview-up"
+	ActionPreviewDown     = "preview-down"
+	ActionOpenHomepage    = "open-homepage"
+	ActionCopyInstall     = "copy-install"
+	ActionAdd             = "add"
+	ActionRemove          = "remove"
+	ActionInstall         = "install"
+	ActionSimilar         = "similar"
+	ActionHelp            = "help"
+)
+
+// binding is an action with its default key
+type binding struct {
+	action      string
+	key         string
+	description string
+}
+
+// bindings lists every action the selector knows
+var bindings = []binding{
+	{ActionToggle, "tab", "Select or unselect the package"},
+	{ActionConfirm, "enter", "Confirm the selection"},
+	{ActionCancel, "esc", "Cancel, or go back from a list of similar packages"},
+	{ActionNextTab, "ctrl-t", "Next tab"},
+	{ActionPrevTab, "shift-tab", "Previous tab"},
+	{ActionHideDeprecated, "f2", "Hide deprecated packages"},
+	{ActionHideListed, "f3", "Hide packages already in the Brewfile"},
+	{ActionSelectedOnly, "f4", "Show selected packages only"},
+	{ActionPreview, "f5", "Show or hide the preview"},
+	{ActionPreviewWider, "alt-left", "Widen the preview"},
+	{ActionPreviewNarrower, "alt-right", "Narrow the preview"},
+	{ActionPreviewUp, "shift-up", "Scroll the preview up"},
+	{ActionPreviewDown, "shift-down", "Scroll the preview down"},
+	{ActionOpenHomepage, "ctrl-o", "Open the homepage in a browser"},
+	{ActionCopyInstall, "ctrl-y", "Copy the install command"},
+	{ActionAdd, "ctrl-s", "Add to the Brewfile now"},
+	{ActionRemove, "ctrl-x", "Remove from the Brewfile now"},
+	{ActionInstall, "ctrl-r", "Install now with brew install"},
+	{ActionSimilar, "ctrl-l", "List packages similar to this one"},
+	{ActionHelp, "f1", "Show or hide this help"},
+}
+
+// keyMap maps key names to actions and back
+type keyMap struct {
+	actions map[string]string // by key
+	keys    map[string]string // by action
+}
+
+// newKeyMap applies configured keys, from action name to key, over the
+// defaults. A configured key takes over from an action bound to it by
+// default, and "none" unbinds an action.
+func newKeyMap(configured map[string]string) (keyMap, error) {
+	km := keyMap{actions: make(map[string]string), keys: make(map[string]string)}
+
+	known := make(map[string]bool, len(bindings))
+	for _, b := range bindings {
+		known[b.action] = true
+	}
+
+	custom := make(map[string]string, len(configured))
+	for action, key := range configured {
+		if !known[action] {
+			return km, fmt.Errorf("unknown action %q in key bindings", action)
+		}
+		name, err := normalizeKey(key)
+		if err != nil {
+			return km, fmt.Errorf("key for %s: %w", action, err)
+		}
+		custom[action] = name
+	}
+
+	for _, b := range bindings {
+		key, ok := custom[b.action]
+		if !ok {
+			key = b.key
+			if hasValue(custom, key) {
+				continue
+			}
+		}
+		if key == "none" {
+			continue
+		}
+		km.actions[key] = b.action
+		km.keys[b.action] = key
+	}
+	return km, nil
+}
+
+func hasValue(m map[string]string, value string) bool {
+	for _, v := range m {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// label returns how the help and header show an action's key, such as
+// "CTRL-O", or "" if it is unbound
+func (km keyMap) label(action string) string {
+	return strings.ToUpper(km.keys[action])
+}
+
+// normalizeKey checks a key name such as "ctrl-o", "alt-left", "F2" or "?"
+// and returns it as eventKey names key presses: in lower case, with
+// modifiers joined by "-" in the order alt, ctrl, shift
+func normalizeKey(key string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(key))
+	if name == "none" {
+		return name, nil
+	}
+
+	base := strings.ReplaceAll(name, "+", "-")
+	mods := make(map[string]bool)
+	for {
+		mod, rest, ok := strings.Cut(base, "-")
+		if !ok || rest == "" || mod != "alt" && mod != "ctrl" && mod != "shift" {
+			break
+		}
+		mods[mod] = true
+		base = rest
+	}
+	if len([]rune(base)) != 1 && !keyNames()[base] {
+		return "", fmt.Errorf("unknown key %q", key)
+	}
+
+	for _, mod := range []string{"shift", "ctrl", "alt"} {
+		if mods[mod] {
+			base = mod + "-" + base
+		}
+	}
+	return base, nil
+}
+
+// keyNames returns the names of special keys, without modifiers
+func keyNames() map[string]bool {
+	names := map[string]bool{"space": true}
+	for _, name := range tcell.KeyNames {
+		name = strings.ToLower(name)
+		if !strings.HasPrefix(name, "ctrl-") {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// eventKey names a key press the way bindings do, such as "ctrl-o",
+// "shift-tab", "alt-left" or "a"
+func eventKey(ev *tcell.EventKey) string {
+	var name string
+	switch ev.Key() {
+	case tcell.KeyRune:
+		name = string(ev.Rune())
+		if name == " " {
+			name = "space"
+		}
+	case tcell.KeyBacktab:
+		return "shift-tab"
+	default:
+		n, ok := tcell.KeyNames[ev.Key()]
+		if !ok {
+			return ""
+		}
+		name = strings.ToLower(n)
+	}
+
+	mods := ev.Modifiers()
+	if mods&tcell.ModShift != 0 && ev.Key() != tcell.KeyRune {
+		name = "shift-" + name
+	}
+	if mods&tcell.ModCtrl != 0 && !strings.HasPrefix(name, "ctrl-") {
+		name = "ctrl-" + name
+	}
+	if mods&tcell.ModAlt != 0 {
+		name = "alt-" + name
+	}
+	return name
+}
+
+// helpLines lists the bound actions with their keys, for the help overlay
+func (km keyMap) helpLines(available func(action string) bool) []string {
+	width := 0
+	for _, b := range bindings {
+		width = max(width, len(km.label(b.action)))
+	}
+
+	var lines []string
+	for _, b := range bindings {
+		if km.keys[b.action] == "" || !available(b.action) {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%-*s  %s", width, km.label(b.action), b.description))
+	}
+	return lines
+}
@@ -1,217 +1,234 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
-	"github.com/ktr0731/go-fuzzyfinder"
 	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/text"
 )
 
-type packageDisplay struct {
-	pkg     api.Package
-	display string
-	index   int
+// ErrCancelled is returned when the user cancels a selector that needs an
+// explicit answer
+var ErrCancelled = errors.New("selection cancelled")
+
+// ShowPackageSelector lets the user pick packages. It returns the selected
+// packages or, if none are, the one under the cursor; ESC returns nothing.
+func ShowPackageSelector(packages []api.Package, existing map[string]bool, opts Options) ([]api.Package, error) {
+	f, err := newFinder(packages, existing, opts, modeSelect)
+	if err != nil {
+		return nil, err
+	}
+	selected, _, err := f.run()
+	return selected, err
 }
 
-func ShowPackageSelector(packages []api.Package, existing map[string]bool) ([]api.Package, error) {
-	// Create a copy and sort packages by token length (shorter = more likely to be searched)
-	sortedPackages := make([]api.Package, len(packages))
-	copy(sortedPackages, packages)
-	
-	sort.Slice(sortedPackages, func(i, j int) bool {
-		// First by token length
-		if len(sortedPackages[i].Token) != len(sortedPackages[j].Token) {
-			return len(sortedPackages[i].Token) < len(sortedPackages[j].Token)
+// ShowExclusionSelector shows packages that will be kept unless the user
+// marks them. It returns the marked packages, which may be none; ESC returns
+// ErrCancelled.
+func ShowExclusionSelector(packages []api.Package, existing map[string]bool, opts Options) ([]api.Package, error) {
+	f, err := newFinder(packages, existing, opts, modeExclude)
+	if err != nil {
+		return nil, err
+	}
+	marked, ok, err := f.run()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrCancelled
+	}
+	return marked, nil
+}
+
+// sortPackages returns a sorted copy of packages
+func sortPackages(packages []api.Package, opts Options) []api.Package {
+	// Shorter names first, as they are more likely to be searched for
+	sorted := make([]api.Package, len(packages))
+	copy(sorted, packages)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if opts.Sort == SortRelevance {
+			return false
+		}
+		// First by token length, unless sorting by name
+		if opts.Sort != "name" && len(sorted[i].Token) != len(sorted[j].Token) {
+			return len(sorted[i].Token) < len(sorted[j].Token)
 		}
 		// Then alphabetically
-		return sortedPackages[i].Token < sortedPackages[j].Token
+		return sorted[i].Token < sorted[j].Token
 	})
-	
-	// Prepare display items with wrapper type
-	items := make([]packageDisplay, len(sortedPackages))
-	for i, pkg := range sortedPackages {
-		// Status indicators
-		var statusIcon string
-		if existing[pkg.Token] {
-			statusIcon = "✅"
-		} else {
-			statusIcon = "  "
-		}
-		
-		// Package type icon
-		var typeIcon string
-		if pkg.Type == "cask" {
-			typeIcon = "🖥️"
-		} else {
-			typeIcon = "⚡"
-		}
-		
-		name := pkg.Token
-		if pkg.FullName != "" && pkg.FullName != pkg.Token {
-			name = fmt.Sprintf("%s (%s)", pkg.Token, pkg.FullName)
-		}
-		
-		desc := pkg.Description
-		if desc == "" {
-			desc = "—"
-		}
-		if len(desc) > 80 {
-			desc = desc[:77] + "..."
-		}
-		
-		version := pkg.Version
-		if version == "" {
-			version = "unknown"
-		}
-		if len(version) > 20 {
-			version = version[:20] + "..."
-		}
-		
-		// Format with clear visual separation using box drawing characters
-		nameStr := truncate(name, 30)
-		versionStr := version
-		if len(versionStr) > 15 {
-			versionStr = versionStr[:12] + "..."
-		}
-		descStr := desc
-		if len(descStr) > 50 {
-			descStr = descStr[:47] + "..."
-		}
-		
-		// Build formatted line with dots as separators to avoid fuzzy finder highlight issues
-		display := fmt.Sprintf("%s %s %-30s · %-15s · %s", 
-			statusIcon,
-			typeIcon,
-			nameStr,
-			versionStr,
-			descStr,
-		)
-		
-		items[i] = packageDisplay{
-			pkg:     pkg,
-			display: display,
-			index:   i,
-		}
+	return sorted
+}
+
+// List columns: the name, the version and the description, as indexes into
+// the widths text.Fit returns
+const (
+	columnName = iota
+	columnVersion
+	columnDesc
+)
+
+// columnSep separates the list's columns
+const columnSep = " · "
+
+// listColumns measures the list's columns for packages. Names and versions
+// are capped so the description keeps room on wide screens.
+func listColumns(packages []api.Package) []text.Column {
+	columns := []text.Column{
+		columnName:    {Min: 12, Max: 40},
+		columnVersion: {Min: 7, Max: 15},
+		columnDesc:    {Min: 10},
 	}
-	
-	// Show fuzzy finder with multi-select
-	indices, err := fuzzyfinder.FindMulti(
-		items,
-		func(i int) string {
-			// Return the formatted display string
-			return items[i].display
-		},
-		fuzzyfinder.WithPreviewWindow(func(i, w, h int) string {
-			if i == -1 {
-				return ""
-			}
-			
-			pkg := items[i].pkg
-			var preview strings.Builder
-			
-			// Header with package name and type
-			typeEmoji := "⚡"
-			typeName := "Formula"
-			if pkg.Type == "cask" {
-				typeEmoji = "🖥️"
-				typeName = "Cask"
-			}
-			
-			preview.WriteString(fmt.Sprintf("%s %s\n", typeEmoji, pkg.Token))
-			preview.WriteString(strings.Repeat("─", min(len(pkg.Token)+3, w)) + "\n\n")
-			
-			// Installation status
-			if existing[pkg.Token] {
-				preview.WriteString("✅ Already in Brewfile\n")
-			} else {
-				preview.WriteString("📦 Not in Brewfile\n")
-			}
-			
-			// Package details
-			preview.WriteString(fmt.Sprintf("📋 Type: %s\n", typeName))
-			
-			if pkg.Version != "" {
-				preview.WriteString(fmt.Sprintf("🏷️  Version: %s\n", pkg.Version))
-			}
-			
-			if pkg.FullName != "" && pkg.FullName != pkg.Token {
-				preview.WriteString(fmt.Sprintf("📛 Full Name: %s\n", pkg.FullName))
-			}
-			
-			// Description
-			if pkg.Description != "" {
-				preview.WriteString(fmt.Sprintf("\n📄 Description:\n%s\n", wordWrap(pkg.Description, w-2)))
-			}
-			
-			// Homepage
-			if pkg.Homepage != "" {
-				preview.WriteString(fmt.Sprintf("\n🌐 Homepage:\n%s\n", pkg.Homepage))
-			}
-			
-			// Installation command preview
-			preview.WriteString(fmt.Sprintf("\n💻 Install command:\nbrew install %s\n", pkg.Token))
-			
-			return preview.String()
-		}),
-		fuzzyfinder.WithPromptString("🔍 Search packages: "),
-		fuzzyfinder.WithHeader("\n   ⚡ Formula   🖥️ Cask   ✅ In Brewfile    ·    TAB: Select   ENTER: Confirm   ESC: Cancel\n   ══════════════════════════════════════════════════════════════════════════════════════════════\n"),
-	)
-	
-	if err != nil {
-		if err == fuzzyfinder.ErrAbort {
-			return nil, nil // User cancelled
-		}
-		return nil, err
+	for _, pkg := range packages {
+		name, version, desc := listFields(pkg)
+		columns[columnName].Natural = max(columns[columnName].Natural, text.Width(name))
+		columns[columnVersion].Natural = max(columns[columnVersion].Natural, text.Width(version))
+		columns[columnDesc].Natural = max(columns[columnDesc].Natural, text.Width(desc))
 	}
-	
-	// Collect selected packages
-	selected := make([]api.Package, len(indices))
-	for i, idx := range indices {
-		selected[i] = items[idx].pkg
+	return columns
+}
+
+// listFields returns what the list shows of a package in each column
+func listFields(pkg api.Package) (name, version, desc string) {
+	name = pkg.Token
+	if pkg.FullName != "" && pkg.FullName != pkg.Token {
+		name = fmt.Sprintf("%s (%s)", pkg.Token, pkg.FullName)
+	}
+
+	version = pkg.Version
+	if version == "" {
+		version = "unknown"
+	}
+
+	desc = pkg.Description
+	if desc == "" {
+		desc = "—"
 	}
-	
-	return selected, nil
+	return name, version, desc
 }
 
-func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s + strings.Repeat(" ", maxLen-len(s))
+// displayLine renders a package's line in the list, with columns of the
+// given widths
+func displayLine(pkg api.Package, existing map[string]bool, t theme, widths []int) string {
+	// Status indicators
+	statusIcon := t.blank
+	if existing[pkg.Token] {
+		statusIcon = t.inBrewfile
 	}
-	return s[:maxLen-3] + "..."
+
+	name, version, desc := listFields(pkg)
+	return t.icon(statusIcon) + " " + t.icon(t.typeIcon(pkg.Type)) + " " +
+		text.Pad(name, widths[columnName]) + columnSep +
+		text.Pad(version, widths[columnVersion]) + columnSep +
+		text.Truncate(desc, widths[columnDesc])
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
+// PackageDetails renders everything known about a package for a window of
+// the given width. It is shared by the preview window and the info command.
+func PackageDetails(pkg api.Package, inBrewfile bool, w int, opts Options) string {
+	t := themeFor(opts.Theme)
+	var preview strings.Builder
+
+	// Header with package name and type
+	typeName := "Formula"
+	if pkg.Type == "cask" {
+		typeName = "Cask"
+	}
+
+	preview.WriteString(fmt.Sprintf("%s %s\n", t.typeIcon(pkg.Type), pkg.Token))
+	preview.WriteString(strings.Repeat("─", min(text.Width(pkg.Token)+3, w)) + "\n\n")
+
+	// Installation status
+	if inBrewfile {
+		preview.WriteString(t.label("✅", "Already in Brewfile") + "\n")
+	} else {
+		preview.WriteString(t.label("📦", "Not in Brewfile") + "\n")
+	}
+
+	if pkg.Deprecated {
+		preview.WriteString(t.label("⚠️ ", "Deprecated") + "\n")
+	}
+
+	// Package details
+	preview.WriteString(fmt.Sprintf("%s%s\n", t.label("📋", "Type: "), typeName))
+
+	if pkg.Version != "" {
+		preview.WriteString(fmt.Sprintf("%s%s\n", t.label("🏷️ ", "Version: "), pkg.Version))
+	}
+
+	if pkg.FullName != "" && pkg.FullName != pkg.Token {
+		preview.WriteString(fmt.Sprintf("%s%s\n", t.label("📛", "Full Name: "), pkg.FullName))
+	}
+
+	if pkg.License != "" {
+		preview.WriteString(fmt.Sprintf("%s%s\n", t.label("⚖️ ", "License: "), pkg.License))
+	}
+
+	if len(pkg.Aliases) > 0 {
+		preview.WriteString(fmt.Sprintf("%s%s\n", t.label("🔗", "Aliases: "), strings.Join(pkg.Aliases, ", ")))
+	}
+
+	// Description
+	if pkg.Description != "" {
+		preview.WriteString(fmt.Sprintf("\n%s\n%s\n", t.label("📄", "Description:"), text.Wrap(pkg.Description, w-2)))
+	}
+
+	// Homepage
+	if pkg.Homepage != "" {
+		preview.WriteString(fmt.Sprintf("\n%s\n%s\n", t.label("🌐", "Homepage:"), pkg.Homepage))
+	}
+
+	// Dependencies
+	if len(pkg.Dependencies) > 0 {
+		preview.WriteString(fmt.Sprintf("\n%s\n%s\n", t.label("🧩", "Dependencies:"), text.Wrap(strings.Join(pkg.Dependencies, ", "), w-2)))
+	}
+
+	// Similar packages, left out until they can be worked out without waiting
+	if opts.similarReady() {
+		if similar := opts.Similar(pkg, similarPreviewLimit); len(similar) > 0 {
+			names := make([]string, len(similar))
+			for i, s := range similar {
+				names[i] = s.Token
+			}
+			preview.WriteString(fmt.Sprintf("\n%s\n%s\n", t.label("🧭", "Similar:"), text.Wrap(strings.Join(names, ", "), w-2)))
+		}
+	}
+
+	// Caveats
+	if pkg.Caveats != "" {
+		preview.WriteString(fmt.Sprintf("\n%s\n%s\n", t.label("💡", "Caveats:"), strings.TrimRight(pkg.Caveats, "\n")))
 	}
-	return b
+
+	// Installation command preview
+	preview.WriteString(fmt.Sprintf("\n%s\n%s\n", t.label("💻", "Install command:"), pkg.InstallCommand()))
+
+	return preview.String()
 }
 
-func wordWrap(text string, width int) string {
-	if width <= 0 {
-		return text
-	}
-	
-	var result strings.Builder
-	words := strings.Fields(text)
-	lineLen := 0
-	
-	for i, word := range words {
-		wordLen := len(word)
-		
-		if i > 0 && lineLen+wordLen+1 > width {
-			result.WriteString("\n")
-			lineLen = 0
-		} else if i > 0 {
-			result.WriteString(" ")
-			lineLen++
+// InstallsLine renders install analytics as a single line
+func InstallsLine(a api.Analytics, opts Options) string {
+	return fmt.Sprintf("%s%s (30d) · %s (90d) · %s (365d)\n",
+		themeFor(opts.Theme).label("📈", "Installs: "),
+		FormatCount(a.Installs30d), FormatCount(a.Installs90d), FormatCount(a.Installs365d))
+}
+
+// FormatCount writes n with thousands separators, e.g. "12,345"
+func FormatCount(n int) string {
+	if n < 0 {
+		return "-" + FormatCount(-n)
+	}
+	digits := strconv.Itoa(n)
+
+	var b strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
 		}
-		
-		result.WriteString(word)
-		lineLen += wordLen
+		b.WriteRune(digit)
 	}
-	
-	return result.String()
-}
\ No newline at end of file
+	return b.String()
+}
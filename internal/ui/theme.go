@@ -0,0 +1,179 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/user/go-brew-search/internal/api"
+	"github.com/user/go-brew-search/internal/text"
+)
+
+// Options control how the selector and package details look
+type Options struct {
+	Sort  string            // "length" (shortest names first), "name" or SortRelevance
+	Theme string            // "emoji" or "ascii"
+	Keys  map[string]string // action name to key
+
+	Data DataSource // where the package data came from; nil hides its age
+	Load Loader     // streams packages into the list while it is open; nil if they are all given
+
+	Filter string // query the list was filtered with, shown in the header
+	Query  string // initial text in the search prompt
+
+	Similar      func(pkg api.Package, limit int) []api.Package // most similar first; nil hides them
+	SimilarReady <-chan struct{}                                // closed once Similar answers without waiting; nil if it always does
+	Actions      Actions
+}
+
+// similarReady reports whether Similar can be asked without blocking the
+// caller
+func (o Options) similarReady() bool {
+	if o.Similar == nil {
+		return false
+	}
+	select {
+	case <-o.SimilarReady:
+		return true
+	default:
+		return o.SimilarReady == nil
+	}
+}
+
+// Actions change packages straight from the selector. Add and Remove return
+// a message for the status bar. A nil action is unavailable.
+type Actions struct {
+	Add     func([]api.Package) (string, error) // add to the Brewfile
+	Remove  func([]api.Package) (string, error) // remove from the Brewfile
+	Install func([]api.Package) error           // install now, with the terminal restored
+}
+
+// DataSource tells how fresh the package data is. It is asked again on every
+// redraw, so it stays right while packages are loading.
+type DataSource interface {
+	Updated() time.Time // when the data was downloaded; zero if unknown
+	Offline() bool      // the data comes from the cache or a snapshot only
+}
+
+// Loader streams packages into an open selector. It calls add as each
+// source finishes, from any goroutine, and returns once all have; an error
+// is shown in the status bar.
+type Loader func(add func([]api.Package)) error
+
+// SortRelevance keeps packages in the order given, for lists that are
+// already ranked
+const SortRelevance = "relevance"
+
+// DefaultOptions matches the built-in configuration
+func DefaultOptions() Options {
+	return Options{Sort: "length", Theme: "emoji"}
+}
+
+// theme holds the markers used for a visual theme
+type theme struct {
+	emoji      bool
+	inBrewfile string
+	formula    string
+	cask       string
+	blank      string
+	iconWidth  int // of the widest marker
+}
+
+func themeFor(name string) theme {
+	t := theme{
+		emoji:      true,
+		inBrewfile: "✅",
+		formula:    "⚡",
+		cask:       "🍺",
+	}
+	if name == "ascii" {
+		t = theme{
+			inBrewfile: "*",
+			formula:    "F",
+			cask:       "C",
+		}
+	}
+	for _, icon := range []string{t.inBrewfile, t.formula, t.cask} {
+		t.iconWidth = max(t.iconWidth, text.Width(icon))
+	}
+	t.blank = strings.Repeat(" ", t.iconWidth)
+	return t
+}
+
+// icon pads a marker to the width of the widest, so the list's columns line
+// up whichever markers a row has
+func (t theme) icon(marker string) string {
+	return text.Pad(marker, t.iconWidth)
+}
+
+func (t theme) typeIcon(pkgType string) string {
+	if pkgType == "cask" {
+		return t.cask
+	}
+	return t.formula
+}
+
+// label prefixes text with an icon in the emoji theme
+func (t theme) label(icon, text string) string {
+	if !t.emoji {
+		return text
+	}
+	return icon + " " + text
+}
+
+// legend explains the list's markers
+func (t theme) legend() string {
+	return t.formula + " Formula   " + t.cask + " Cask   " + t.inBrewfile + " In Brewfile"
+}
+
+// marked is the marker of a selected package, or of one marked to skip
+func (t theme) marked(skip bool) string {
+	switch {
+	case skip && t.emoji:
+		return "✗"
+	case skip:
+		return "x"
+	case t.emoji:
+		return "●"
+	}
+	return "+"
+}
+
+// dataAge shows how old the package data is, or "" if that is unknown
+func (t theme) dataAge(opts Options) string {
+	if opts.Data == nil {
+		return ""
+	}
+	updated := opts.Data.Updated()
+	if updated.IsZero() {
+		return ""
+	}
+
+	text := "Package data from " + FormatAge(time.Since(updated)) + " ago"
+	if opts.Data.Offline() {
+		text += " (offline)"
+	}
+	return t.label("📅", text)
+}
+
+// FormatAge renders a duration at a readable precision, such as "2d 3h" or
+// "14m"
+func FormatAge(d time.Duration) string {
+	if d < 0 {
+		d = -d
+	}
+
+	days := int(d / (24 * time.Hour))
+	hours := int(d % (24 * time.Hour) / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	case minutes > 0:
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return "<1m"
+}